@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// Reasons of rejected execution requests, as reported by the metrics.
+const (
+	rejectClientRate = "client_rate"
+	rejectTopicRate  = "topic_rate"
+	rejectInFlight   = "in_flight"
+)
+
+const (
+	defaultExecutionQueueTimeout = 30 * time.Second
+
+	// Limiters of clients and topics idle for longer are dropped.
+	limiterIdleTimeout = 10 * time.Minute
+	limiterPruneSize   = 1000
+)
+
+var errHeadBusy = errors.New("too many executions in flight")
+
+// AdmissionConfig configures how many execution requests the head accepts. Rates are in requests
+// per second, 0 disables the limit.
+type AdmissionConfig struct {
+	ClientRate   float64
+	ClientBurst  int
+	TopicRate    float64
+	TopicBurst   int
+	MaxInFlight  int           // executions running at once, 0 for no limit
+	MaxQueued    int           // executions waiting for a slot, beyond which requests are rejected
+	QueueTimeout time.Duration // how long an execution waits for a slot, 0 to reject right away
+
+	// Networks of the proxies trusted to give the IP of clients in X-Forwarded-For. Without them
+	// clients are the remote address of their connection.
+	TrustedProxies []string
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// keyedLimiters holds a token bucket per client or topic.
+type keyedLimiters struct {
+	lock     sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*limiterEntry
+}
+
+func newKeyedLimiters(perSecond float64, burst int) *keyedLimiters {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &keyedLimiters{
+		limit:    rate.Limit(perSecond),
+		burst:    max(burst, 1),
+		limiters: make(map[string]*limiterEntry),
+	}
+}
+
+// reserve takes n tokens of the key. If there are not enough, it takes none and returns how long
+// to wait before retrying. n must not be over the burst.
+func (k *keyedLimiters) reserve(key string, n int, now time.Time) (*rate.Reservation, time.Duration) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if len(k.limiters) >= limiterPruneSize {
+		for idle, entry := range k.limiters {
+			if now.Sub(entry.lastSeen) > limiterIdleTimeout {
+				delete(k.limiters, idle)
+			}
+		}
+	}
+	entry, ok := k.limiters[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.limiters[key] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, n)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return nil, delay
+	}
+	return reservation, 0
+}
+
+// Admission rate limits the execution requests of clients and topics and bounds the number of
+// executions in flight. A nil Admission admits everything.
+type Admission struct {
+	clients *keyedLimiters
+	topics  *keyedLimiters
+
+	slots        chan struct{}
+	queued       chan struct{}
+	queueTimeout time.Duration
+	now          func() time.Time
+}
+
+// NewAdmission creates the admission control of the head, nil if no limit is configured.
+func NewAdmission(cfg AdmissionConfig) (*Admission, error) {
+	if cfg.ClientRate < 0 || cfg.TopicRate < 0 || cfg.MaxInFlight < 0 || cfg.MaxQueued < 0 || cfg.QueueTimeout < 0 {
+		return nil, errors.New("admission limits must not be negative")
+	}
+	if cfg.ClientRate == 0 && cfg.TopicRate == 0 && cfg.MaxInFlight == 0 {
+		return nil, nil
+	}
+
+	a := &Admission{
+		clients:      newKeyedLimiters(cfg.ClientRate, cfg.ClientBurst),
+		topics:       newKeyedLimiters(cfg.TopicRate, cfg.TopicBurst),
+		queueTimeout: cfg.QueueTimeout,
+		now:          time.Now,
+	}
+	if cfg.MaxInFlight > 0 {
+		a.slots = make(chan struct{}, cfg.MaxInFlight)
+		a.queued = make(chan struct{}, cfg.MaxQueued)
+	}
+	return a, nil
+}
+
+// RateLimit returns a middleware rejecting execution requests beyond the rate of their client or
+// topic with 429. Batches count as one request per item, and batches larger than a burst are
+// rejected with 400. Clients are the authenticated API client, or the remote IP without
+// authentication, as found by the IP extractor of the server.
+func (a *Admission) RateLimit() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if a == nil || (a.clients == nil && a.topics == nil) {
+			return next
+		}
+
+		return func(ctx echo.Context) error {
+			body, err := readRequestBody(ctx)
+			if err != nil {
+				return err
+			}
+
+			topics := requestTopics(ctx.Path(), body)
+			if err := a.checkSize(topics); err != nil {
+				return badRequest(err.Error())
+			}
+			client, ok := ctx.Get(contextKeyAPIClient).(string)
+			if !ok {
+				client = ctx.RealIP()
+			}
+			reason, delay, ok := a.allow(client, topics)
+			if !ok {
+				admissionRejected.WithLabelValues(reason).Inc()
+				if delay > 0 {
+					ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				}
+				return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded (%s)", reason))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// checkSize checks that an execution request for the topics fits in the bursts of its client and
+// topics, so that it can ever be admitted.
+func (a *Admission) checkSize(topics []string) error {
+	if a == nil {
+		return nil
+	}
+	if a.clients != nil && len(topics) > a.clients.burst {
+		return fmt.Errorf("batch of %d requests is larger than the client burst of %d", len(topics), a.clients.burst)
+	}
+	if a.topics != nil {
+		for topic, n := range countTopics(topics) {
+			if n > a.topics.burst {
+				return fmt.Errorf("batch has %d requests for topic %s, more than the topic burst of %d", n, topic, a.topics.burst)
+			}
+		}
+	}
+	return nil
+}
+
+// allow takes the rate limit tokens of an execution request of the client for the topics, all or
+// none. If the request is over a limit, it returns the limit and how long to wait before retrying.
+func (a *Admission) allow(client string, topics []string) (string, time.Duration, bool) {
+	if a == nil {
+		return "", 0, true
+	}
+	now := a.now()
+	var reserved []*rate.Reservation
+	cancel := func() {
+		for _, reservation := range reserved {
+			reservation.CancelAt(now)
+		}
+	}
+
+	if a.clients != nil {
+		reservation, delay := a.clients.reserve(client, len(topics), now)
+		if reservation == nil {
+			return rejectClientRate, delay, false
+		}
+		reserved = append(reserved, reservation)
+	}
+	if a.topics != nil {
+		for topic, n := range countTopics(topics) {
+			reservation, delay := a.topics.reserve(topic, n, now)
+			if reservation == nil {
+				cancel()
+				return rejectTopicRate, delay, false
+			}
+			reserved = append(reserved, reservation)
+		}
+	}
+	return "", 0, true
+}
+
+// countTopics counts the requests of each topic.
+func countTopics(topics []string) map[string]int {
+	counts := make(map[string]int, len(topics))
+	for _, topic := range topics {
+		counts[topic]++
+	}
+	return counts
+}
+
+// apiIPExtractor returns how the REST API finds the IP of its clients: the remote address of the
+// connection, or the X-Forwarded-For header as set by the trusted proxies only.
+func apiIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy network %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// Acquire takes an execution slot, waiting in the queue for up to the queue timeout if all are
+// taken. The returned function frees the slot.
+func (a *Admission) Acquire(ctx context.Context) (func(), error) {
+	if a == nil || a.slots == nil {
+		return func() {}, nil
+	}
+
+	release := func() {
+		<-a.slots
+		executionsInFlight.Dec()
+	}
+	select {
+	case a.slots <- struct{}{}:
+		executionsInFlight.Inc()
+		return release, nil
+	default:
+	}
+
+	// Wait in the queue, if there is room left.
+	select {
+	case a.queued <- struct{}{}:
+	default:
+		admissionRejected.WithLabelValues(rejectInFlight).Inc()
+		return nil, errHeadBusy
+	}
+	executionsQueued.Inc()
+	defer func() {
+		<-a.queued
+		executionsQueued.Dec()
+	}()
+
+	timer := time.NewTimer(a.queueTimeout)
+	defer timer.Stop()
+	select {
+	case a.slots <- struct{}{}:
+		executionsInFlight.Inc()
+		return release, nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	admissionRejected.WithLabelValues(rejectInFlight).Inc()
+	return nil, errHeadBusy
+}
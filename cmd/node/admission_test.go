@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionRateLimit(t *testing.T) {
+	admission, err := NewAdmission(AdmissionConfig{ClientRate: 1, ClientBurst: 3, TopicRate: 1, TopicBurst: 2})
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	admission.now = func() time.Time { return now }
+
+	e := echo.New()
+	handler := admission.RateLimit()(func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })
+	statusAt := func(path string, client string, body string) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		ctx := e.NewContext(r, httptest.NewRecorder())
+		ctx.SetPath(path)
+		ctx.Set(contextKeyAPIClient, client)
+		if he, ok := handler(ctx).(*echo.HTTPError); ok {
+			return he.Code
+		}
+		return http.StatusOK
+	}
+	status := func(client string, body string) int { return statusAt(headapi.PathExecute, client, body) }
+	batch := func(client string, body string) int { return statusAt(headapi.PathExecuteBatch, client, body) }
+
+	// Topic 1 allows a burst of 2.
+	require.Equal(t, http.StatusOK, status("a", `{"topic":"1"}`))
+	require.Equal(t, http.StatusOK, status("b", `{"topic":"1"}`))
+	require.Equal(t, http.StatusTooManyRequests, status("c", `{"topic":"1"}`))
+
+	// Client a has 2 requests left of its burst, batch items count one each.
+	require.Equal(t, http.StatusTooManyRequests, batch("a", `{"requests":[{"topic":"2"},{"topic":"3"},{"topic":"4"}]}`))
+	require.Equal(t, http.StatusOK, batch("a", `{"requests":[{"topic":"2"},{"topic":"3"}]}`))
+	require.Equal(t, http.StatusTooManyRequests, status("a", `{"topic":"5"}`))
+	// The rejected batch took no token of topic 4.
+	require.Equal(t, http.StatusOK, batch("d", `{"requests":[{"topic":"4"},{"topic":"4"}]}`))
+
+	// Tokens are taken for all limits or none: client c was refused by topic 1, not its own limit.
+	require.Equal(t, http.StatusOK, batch("c", `{"requests":[{"topic":"6"},{"topic":"7"},{"topic":"8"}]}`))
+
+	// Batches which could never be admitted are bad requests.
+	require.Equal(t, http.StatusBadRequest, batch("e", `{"requests":[{"topic":"6"},{"topic":"7"},{"topic":"8"},{"topic":"9"}]}`))
+	require.Equal(t, http.StatusBadRequest, batch("e", `{"requests":[{"topic":"9"},{"topic":"9"},{"topic":"9"}]}`))
+	require.Equal(t, http.StatusOK, status("e", `{"topic":"9"}`))
+
+	// Tokens come back over time.
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, status("a", `{"topic":"1"}`))
+}
+
+func TestAPIIPExtractor(t *testing.T) {
+	request := func(remote string, forwardedFor string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, headapi.PathExecute, nil)
+		r.RemoteAddr = remote + ":4321"
+		r.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		r.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		return r
+	}
+
+	// Without trusted proxies, forwarding headers are ignored.
+	extract, err := apiIPExtractor(nil)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7", extract(request("203.0.113.7", "198.51.100.1")))
+	require.Equal(t, "10.0.0.5", extract(request("10.0.0.5", "198.51.100.1")))
+
+	// Only the trusted proxies can forward the client IP.
+	extract, err = apiIPExtractor([]string{"10.0.0.0/24"})
+	require.NoError(t, err)
+	require.Equal(t, "198.51.100.1", extract(request("10.0.0.5", "198.51.100.1")))
+	require.Equal(t, "203.0.113.7", extract(request("203.0.113.7", "198.51.100.1")))
+	require.Equal(t, "192.168.1.5", extract(request("192.168.1.5", "198.51.100.1")))
+
+	_, err = apiIPExtractor([]string{"10.0.0.5"})
+	require.Error(t, err)
+}
+
+func TestAdmissionInFlight(t *testing.T) {
+	admission, err := NewAdmission(AdmissionConfig{MaxInFlight: 1, MaxQueued: 1, QueueTimeout: time.Minute})
+	require.NoError(t, err)
+
+	release, err := admission.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// The second execution waits for the slot, the third finds the queue full.
+	acquired := make(chan func())
+	go func() {
+		release, err := admission.Acquire(context.Background())
+		require.NoError(t, err)
+		acquired <- release
+	}()
+	require.Eventually(t, func() bool { return len(admission.queued) == 1 }, time.Second, time.Millisecond)
+	_, err = admission.Acquire(context.Background())
+	require.ErrorIs(t, err, errHeadBusy)
+
+	release()
+	(<-acquired)()
+
+	// Without queue timeout a busy head rejects right away.
+	admission, err = NewAdmission(AdmissionConfig{MaxInFlight: 1, MaxQueued: 1})
+	require.NoError(t, err)
+	_, err = admission.Acquire(context.Background())
+	require.NoError(t, err)
+	_, err = admission.Acquire(context.Background())
+	require.ErrorIs(t, err, errHeadBusy)
+
+	// No limit configured.
+	admission, err = NewAdmission(AdmissionConfig{})
+	require.NoError(t, err)
+	require.Nil(t, admission)
+	release, err = admission.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
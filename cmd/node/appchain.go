@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -22,7 +23,8 @@ import (
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Exponential backoff retry settings
@@ -38,9 +40,8 @@ const NUM_REGISTRATION_RETRY_MIN_DELAY = 1
 const NUM_REGISTRATION_RETRY_MAX_DELAY = 2
 const NUM_STAKING_RETRY_MIN_DELAY = 1
 const NUM_STAKING_RETRY_MAX_DELAY = 2
-const REPUTER_TOPIC_SUFFIX = "/reputer"
 
-func getAlloraClient(config AppChainConfig) (*cosmosclient.Client, error) {
+func getAlloraClient(config AppChainConfig, nodeRPCAddress string, log zerolog.Logger) (*cosmosclient.Client, error) {
 	// create a allora client instance
 	ctx := context.Background()
 	userHomeDir, _ := os.UserHomeDir()
@@ -55,95 +56,107 @@ func getAlloraClient(config AppChainConfig) (*cosmosclient.Client, error) {
 		err = os.MkdirAll(alloraClientHome, 0755)
 		if err != nil {
 			log.Warn().Err(err).Str("directory", alloraClientHome).Msg("Cannot create allora client home directory")
-			config.SubmitTx = false
 			return nil, err
 		}
 		log.Info().Err(err).Str("directory", alloraClientHome).Msg("allora client home directory created")
 	}
 
 	client, err := cosmosclient.New(ctx,
-		cosmosclient.WithNodeAddress(config.NodeRPCAddress),
+		cosmosclient.WithNodeAddress(nodeRPCAddress),
 		cosmosclient.WithAddressPrefix(config.AddressPrefix),
 		cosmosclient.WithHome(alloraClientHome),
 		cosmosclient.WithGas(config.Gas),
 		cosmosclient.WithGasAdjustment(config.GasAdjustment),
 	)
 	if err != nil {
-		log.Warn().Err(err).Msg("unable to create an allora blockchain client")
-		config.SubmitTx = false
+		log.Warn().Err(err).Str("endpoint", nodeRPCAddress).Msg("unable to create an allora blockchain client")
 		return nil, err
 	}
 	return &client, nil
 }
 
-// create a new appchain client that we can use
+// create a new appchain client that we can use.
+// SubmitTx is set on the returned client only if an account was loaded that can sign transactions.
 func NewAppChain(config AppChainConfig, log zerolog.Logger) (*AppChain, error) {
-	config.SubmitTx = true
-	client, err := getAlloraClient(config)
+	config.SubmitTx = false
+	endpoints := NewEndpointPool(config.NodeRPCAddresses, func(address string) (*cosmosclient.Client, error) {
+		return getAlloraClient(config, address, log)
+	}, log)
+	err := endpoints.Probe(context.Background())
 	if err != nil {
-		config.SubmitTx = false
 		return nil, err
 	}
+	client := endpoints.Primary()
+
+	// this is terrible, no isConnected as part of this code path
+	if client.Context().ChainID == "" {
+		return nil, errors.New("allora client is not connected to a chain")
+	}
+
 	var account cosmosaccount.Account
+	var address string
 	// if we're giving a keyring ring name, with no mnemonic restore
-	if config.AddressRestoreMnemonic == "" && config.AddressKeyName != "" {
+	if config.AddressRestoreMnemonic.Empty() && config.AddressKeyName != "" {
 		// get account from the keyring
 		account, err = client.Account(config.AddressKeyName)
 		if err != nil {
-			config.SubmitTx = false
 			log.Warn().Err(err).Msg("could not retrieve account from keyring")
 		}
-	} else if config.AddressRestoreMnemonic != "" && config.AddressKeyName != "" {
+	} else if !config.AddressRestoreMnemonic.Empty() && config.AddressKeyName != "" {
 		// restore from mnemonic
-		account, err = client.AccountRegistry.Import(config.AddressKeyName, config.AddressRestoreMnemonic, config.AddressAccountPassphrase)
+		account, err = client.AccountRegistry.Import(config.AddressKeyName, config.AddressRestoreMnemonic.Reveal(), config.AddressAccountPassphrase.Reveal())
 		if err != nil {
 			if err.Error() == "account already exists" {
 				account, err = client.Account(config.AddressKeyName)
 			}
 
 			if err != nil {
-				config.SubmitTx = false
 				log.Error().Err(err).Msg("error getting account")
 			}
 		}
+		if err == nil {
+			// The account is in the keyring now, later connections load it from there.
+			config.AddressRestoreMnemonic.Wipe()
+			config.AddressAccountPassphrase.Wipe()
+		}
 	} else {
-		log.Warn().Msg("no allora account was loaded")
-		return nil, nil
+		err = errors.New("no allora account configured")
+		log.Warn().Msg("no allora account was loaded, connecting read-only")
 	}
 
-	address, err := account.Address(config.AddressPrefix)
-	if err != nil {
-		config.SubmitTx = false
-		log.Warn().Err(err).Msg("could not retrieve allora blockchain address, transactions will not be submitted to chain")
-	} else {
-		log.Info().Str("address", address).Msg("allora blockchain address loaded")
+	if err == nil {
+		address, err = account.Address(config.AddressPrefix)
+		if err != nil {
+			log.Warn().Err(err).Msg("could not retrieve allora blockchain address, transactions will not be submitted to chain")
+		} else {
+			log.Info().Str("address", address).Msg("allora blockchain address loaded")
+			config.SubmitTx = true
+		}
 	}
 
-	// Create query client
-	queryClient := emissionstypes.NewQueryClient(client.Context())
+	// Create query client, failing over between the endpoints
+	queryClient := emissionstypes.NewQueryClient(failoverConn{endpoints})
 
 	// Create bank client
-	bankClient := banktypes.NewQueryClient(client.Context())
-
-	// this is terrible, no isConnected as part of this code path
-	if client.Context().ChainID == "" {
-		return nil, nil
-	}
+	bankClient := banktypes.NewQueryClient(failoverConn{endpoints})
 
 	appchain := &AppChain{
 		Address:              address,
 		Account:              account,
 		Logger:               log,
 		Client:               client,
+		Endpoints:            endpoints,
 		EmissionsQueryClient: queryClient,
 		BankQueryClient:      bankClient,
 		Config:               config,
 	}
 
-	if config.NodeRole == blockless.WorkerNode {
+	if config.DryRun {
+		appchain.Logger.Info().Msg("Dry run, not registering with blockchain")
+	} else if config.NodeRole == blockless.WorkerNode && config.SubmitTx {
 		registerWithBlockchain(appchain)
 	} else {
-		appchain.Logger.Info().Msg("Node is not a worker, not registering with blockchain")
+		appchain.Logger.Info().Msg("Node is not a worker or cannot sign, not registering with blockchain")
 	}
 	return appchain, nil
 }
@@ -308,12 +321,19 @@ func (ap *AppChain) SendDataWithRetry(ctx context.Context, req sdktypes.Msg, Max
 	var txResp *cosmosclient.Response
 	var err error
 	for retryCount := 0; retryCount <= MaxRetries; retryCount++ {
-		txResponse, err := ap.Client.BroadcastTx(ctx, ap.Account, req)
+		var txResponse cosmosclient.Response
+		txResponse, err = ap.broadcastTx(ctx, req)
 		txResp = &txResponse
 		if err == nil {
 			ap.Logger.Info().Str("Tx Hash:", txResp.TxHash).Msg("Success: " + SuccessMsg)
 			break
 		}
+		// Retrying does not help if the tx can never be sent as is, or not before the fee budget window ends,
+		// nor once the nonce was fulfilled by another tx.
+		if errors.Is(err, errGasAboveLimit) || errors.Is(err, errFeeBudgetExceeded) || errors.Is(err, errDryRun) ||
+			errors.Is(err, emissionstypes.ErrNonceAlreadyFulfilled) || retryCount == MaxRetries {
+			break
+		}
 		// Log the error for each retry.
 		ap.Logger.Info().Err(err).Msgf("Failed: "+SuccessMsg+", retrying... (Retry %d/%d)", retryCount, MaxRetries)
 		// Generate a random number between MinDelay and MaxDelay
@@ -321,13 +341,56 @@ func (ap *AppChain) SendDataWithRetry(ctx context.Context, req sdktypes.Msg, Max
 		// Apply exponential backoff to the random delay
 		backoffDelay := randomDelay << retryCount
 		// Wait for the calculated delay before retrying
-		time.Sleep(time.Duration(backoffDelay) * time.Second)
+		if !sleepContext(ctx, time.Duration(backoffDelay)*time.Second) {
+			break
+		}
 	}
 	return txResp, err
 }
 
+// Reasons for the leader to reject a bundle.
+const (
+	bundleRejectedUnregisteredPeer = "unregistered_peer"
+	bundleRejectedInvalidOutput    = "invalid_output"
+	bundleRejectedMissingBundle    = "missing_bundle"
+	bundleRejectedTopicMismatch    = "topic_mismatch"
+	bundleRejectedDuplicateReputer = "duplicate_reputer"
+)
+
+// countLeaderBundle counts a bundle received by the leader, rejected for the given reason or accepted if there is none.
+func countLeaderBundle(topicId uint64, mode string, reason string) {
+	outcome := "accepted"
+	if reason != "" {
+		outcome = "rejected"
+	}
+	leaderBundles.WithLabelValues(strconv.FormatUint(topicId, 10), mode, outcome, reason).Inc()
+}
+
+// updateBalance refreshes the wallet balance metric of the node account.
+func (ap *AppChain) updateBalance(ctx context.Context) {
+	if ap.Address == "" {
+		return
+	}
+	resp, err := ap.BankQueryClient.Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: ap.Address,
+		Denom:   chainParams.DefaultBondDenom,
+	})
+	if err != nil {
+		ap.Logger.Debug().Err(err).Msg("could not get the balance of the node account")
+		return
+	}
+	if resp.Balance == nil {
+		return
+	}
+	balance, _ := new(big.Float).SetInt(resp.Balance.Amount.BigInt()).Float64()
+	walletBalance.WithLabelValues(ap.Address, resp.Balance.Denom).Set(balance)
+}
+
 // Sending Inferences/Forecasts to the AppChain
 func (ap *AppChain) SendWorkerModeData(ctx context.Context, topicId uint64, results aggregate.Results) {
+	ctx, span := tracer.Start(ctx, "leader.aggregate", trace.WithAttributes(attrTopic.Int64(int64(topicId)), attrMode.String(WorkerModeWorker)))
+	defer span.End()
+
 	// Aggregate the inferences from all peers/workers
 	WorkerDataBundles := make([]*emissionstypes.WorkerDataBundle, 0)
 	var nonce *emissionstypes.Nonce
@@ -340,16 +403,22 @@ func (ap *AppChain) SendWorkerModeData(ctx context.Context, topicId uint64, resu
 				Libp2PKey: peer.String(),
 			})
 			if err != nil {
-				ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("error getting worker peer address from chain, worker not registered? Ignoring peer.")
-				continue
+				if !ap.Config.DryRun {
+					ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("error getting worker peer address from chain, worker not registered? Ignoring peer.")
+					countLeaderBundle(topicId, WorkerModeWorker, bundleRejectedUnregisteredPeer)
+					continue
+				}
+				ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("dry run: worker peer address not found on chain, keeping bundle.")
+			} else {
+				ap.Logger.Debug().Str("worker address", res.Address).Msgf("%+v", result.Result)
 			}
-			ap.Logger.Debug().Str("worker address", res.Address).Msgf("%+v", result.Result)
 
 			// Parse the result from the worker to get the inference and forecasts
 			var value WorkerDataResponse
 			err = json.Unmarshal([]byte(result.Result.Stdout), &value)
 			if err != nil {
 				ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("error extracting WorkerDataBundle from stdout, ignoring bundle.")
+				countLeaderBundle(topicId, WorkerModeWorker, bundleRejectedInvalidOutput)
 				continue
 			}
 			if nonce == nil {
@@ -358,45 +427,50 @@ func (ap *AppChain) SendWorkerModeData(ctx context.Context, topicId uint64, resu
 			// Here reputer leader can choose to validate data further to ensure set is correct and act accordingly
 			if value.WorkerDataBundle == nil {
 				ap.Logger.Warn().Str("peer", peer.String()).Msg("WorkerDataBundle is nil from stdout, ignoring bundle.")
+				countLeaderBundle(topicId, WorkerModeWorker, bundleRejectedMissingBundle)
 				continue
 			}
 			if value.WorkerDataBundle.InferenceForecastsBundle == nil {
 				ap.Logger.Warn().Str("peer", peer.String()).Msg("InferenceForecastsBundle is nil from stdout, ignoring bundle.")
+				countLeaderBundle(topicId, WorkerModeWorker, bundleRejectedMissingBundle)
 				continue
 			}
 			if value.WorkerDataBundle.InferenceForecastsBundle.Inference != nil &&
 				value.WorkerDataBundle.InferenceForecastsBundle.Inference.TopicId != topicId {
 				ap.Logger.Warn().Str("peer", peer.String()).Msg("InferenceForecastsBundle topicId does not match with request topic, ignoring bundle.")
+				countLeaderBundle(topicId, WorkerModeWorker, bundleRejectedTopicMismatch)
 				continue
 			}
 
 			// Append the WorkerDataBundle (only) to the WorkerDataBundles slice
 			WorkerDataBundles = append(WorkerDataBundles, value.WorkerDataBundle)
+			countLeaderBundle(topicId, WorkerModeWorker, "")
 		}
 	}
 
+	span.SetAttributes(attribute.Int("allora.bundles", len(WorkerDataBundles)))
 	if nonce == nil {
 		ap.Logger.Warn().Msg("No valid WorkerDataBundles with nonces found, not sending data to the chain")
 		return
 	}
+	span.SetAttributes(attrNonce.Int64(nonce.BlockHeight))
 
-	// Make 1 request per worker
-	req := &emissionstypes.MsgInsertBulkWorkerPayload{
-		Sender:            ap.Address,
-		Nonce:             nonce,
-		TopicId:           topicId,
-		WorkerDataBundles: WorkerDataBundles,
-	}
+	payload := workerBulkPayload(ap.Address, topicId, nonce, WorkerDataBundles)
 	// Print req as JSON to the log
-	reqJSON, err := json.Marshal(req)
+	reqJSON, err := json.Marshal(payload.build(payload.all()))
 	if err != nil {
 		ap.Logger.Error().Err(err).Msg("Error marshaling MsgInsertBulkWorkerPayload to print Msg as JSON")
 	} else {
 		ap.Logger.Info().Str("req_json", string(reqJSON)).Msg("Sending Worker Mode Data")
 	}
 
+	if ap.Config.DryRun {
+		ap.sendBulkPayload(ctx, payload, NUM_WORKER_RETRIES, NUM_WORKER_RETRY_MIN_DELAY, NUM_WORKER_RETRY_MAX_DELAY)
+		return
+	}
+
 	go func() {
-		_, _ = ap.SendDataWithRetry(ctx, req, NUM_WORKER_RETRIES, NUM_WORKER_RETRY_MIN_DELAY, NUM_WORKER_RETRY_MAX_DELAY, "Sent Worker Leader Data")
+		ap.sendBulkPayload(ctx, payload, NUM_WORKER_RETRIES, NUM_WORKER_RETRY_MIN_DELAY, NUM_WORKER_RETRY_MAX_DELAY)
 	}()
 }
 
@@ -528,6 +602,9 @@ func (ap *AppChain) getStakeWeightedBlockHeights(
 
 // Sending Losses to the AppChain
 func (ap *AppChain) SendReputerModeData(ctx context.Context, topicId uint64, results aggregate.Results) {
+	ctx, span := tracer.Start(ctx, "leader.aggregate", trace.WithAttributes(attrTopic.Int64(int64(topicId)), attrMode.String(WorkerModeReputer)))
+	defer span.End()
+
 	// Aggregate the forecast from reputer leader
 	var valueBundles []*emissionstypes.ReputerValueBundle
 	var reputerAddrs []*string
@@ -545,16 +622,24 @@ func (ap *AppChain) SendReputerModeData(ctx context.Context, topicId uint64, res
 			res, err := ap.EmissionsQueryClient.GetReputerAddressByP2PKey(ctx, &emissionstypes.QueryReputerAddressByP2PKeyRequest{
 				Libp2PKey: peer.String(),
 			})
+			var reputerAddress string
 			if err != nil {
-				ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("error getting reputer peer address from chain, worker not registered? Ignoring peer.")
-				continue
+				if !ap.Config.DryRun {
+					ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("error getting reputer peer address from chain, worker not registered? Ignoring peer.")
+					countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedUnregisteredPeer)
+					continue
+				}
+				// Stand in for the address in the vote, the stake lookup fails over to an unweighted vote.
+				ap.Logger.Warn().Err(err).Str("peer", peer.String()).Msg("dry run: reputer peer address not found on chain, keeping bundle.")
+				reputerAddress = peer.String()
 			} else {
 				// Print the address of the reputer
 				ap.Logger.Info().Str("Reputer Address", res.Address).Msg("Reputer Address")
+				reputerAddress = res.Address
 			}
 
-			if _, ok := reputerAddrSet[res.Address]; !ok {
-				reputerAddrSet[res.Address] = true
+			if _, ok := reputerAddrSet[reputerAddress]; !ok {
+				reputerAddrSet[reputerAddress] = true
 
 				// Parse the result from the reputer to get the losses
 				// Parse the result from the worker to get the inferences and forecasts
@@ -562,27 +647,34 @@ func (ap *AppChain) SendReputerModeData(ctx context.Context, topicId uint64, res
 				err = json.Unmarshal([]byte(result.Result.Stdout), &value)
 				if err != nil {
 					ap.Logger.Warn().Err(err).Str("peer", peer.String()).Str("Value", result.Result.Stdout).Msg("error extracting ReputerDataResponse from stdout, ignoring bundle.")
+					countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedInvalidOutput)
 					continue
 				}
 
 				// Here reputer leader can choose to validate data further to ensure set is correct and act accordingly
 				if value.ReputerValueBundle == nil {
 					ap.Logger.Warn().Str("peer", peer.String()).Msg("ReputerValueBundle is nil from stdout, ignoring bundle.")
+					countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedMissingBundle)
 					continue
 				}
 				if value.ReputerValueBundle.ValueBundle == nil {
 					ap.Logger.Warn().Str("peer", peer.String()).Msg("ValueBundle is nil from stdout, ignoring bundle.")
+					countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedMissingBundle)
 					continue
 				}
 				if value.ReputerValueBundle.ValueBundle.TopicId != topicId {
 					ap.Logger.Warn().Str("peer", peer.String()).Msg("ReputerValueBundle topicId does not match with request topicId, ignoring bundle.")
+					countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedTopicMismatch)
 					continue
 				}
 				// Append the WorkerDataBundle (only) to the WorkerDataBundles slice
 				valueBundles = append(valueBundles, value.ReputerValueBundle)
-				reputerAddrs = append(reputerAddrs, &res.Address)
-				blockCurrentToReputer[value.BlockHeight] = append(blockCurrentToReputer[value.BlockHeight], res.Address)
-				blockEvalToReputer[value.BlockHeightEval] = append(blockEvalToReputer[value.BlockHeightEval], res.Address)
+				countLeaderBundle(topicId, WorkerModeReputer, "")
+				reputerAddrs = append(reputerAddrs, &reputerAddress)
+				blockCurrentToReputer[value.BlockHeight] = append(blockCurrentToReputer[value.BlockHeight], reputerAddress)
+				blockEvalToReputer[value.BlockHeightEval] = append(blockEvalToReputer[value.BlockHeightEval], reputerAddress)
+			} else {
+				countLeaderBundle(topicId, WorkerModeReputer, bundleRejectedDuplicateReputer)
 			}
 		} else {
 			ap.Logger.Warn().Msg("No peers in the result, ignoring")
@@ -605,6 +697,7 @@ func (ap *AppChain) SendReputerModeData(ctx context.Context, topicId uint64, res
 		return
 	}
 	nonceCurrent = &emissionstypes.Nonce{BlockHeight: blockCurrentHeight}
+	span.SetAttributes(attrNonce.Int64(blockCurrentHeight))
 
 	// Remove those bundles that do not come from the current block height
 	var valueBundlesFiltered []*emissionstypes.ReputerValueBundle
@@ -623,25 +716,25 @@ func (ap *AppChain) SendReputerModeData(ctx context.Context, topicId uint64, res
 				Msg("Rejected Bundle, non-matching nonces.")
 		}
 	}
+	span.SetAttributes(attribute.Int("allora.bundles", len(valueBundles)))
 
-	// Make 1 request per worker
-	req := &emissionstypes.MsgInsertBulkReputerPayload{
-		Sender: ap.Address,
-		ReputerRequestNonce: &emissionstypes.ReputerRequestNonce{
-			ReputerNonce: nonceCurrent,
-		},
-		TopicId:             topicId,
-		ReputerValueBundles: valueBundles,
-	}
+	payload := reputerBulkPayload(ap.Address, topicId, &emissionstypes.ReputerRequestNonce{
+		ReputerNonce: nonceCurrent,
+	}, valueBundles)
 	// Print req as JSON to the log
-	reqJSON, err := json.Marshal(req)
+	reqJSON, err := json.Marshal(payload.build(payload.all()))
 	if err != nil {
 		ap.Logger.Error().Err(err).Msg("Error marshaling MsgInsertBulkReputerPayload to print Msg as JSON")
 	} else {
 		ap.Logger.Info().Str("req_json", string(reqJSON)).Msg("Sending Reputer Mode Data")
 	}
 
+	if ap.Config.DryRun {
+		ap.sendBulkPayload(ctx, payload, NUM_REPUTER_RETRIES, NUM_REPUTER_RETRY_MIN_DELAY, NUM_REPUTER_RETRY_MAX_DELAY)
+		return
+	}
+
 	go func() {
-		_, _ = ap.SendDataWithRetry(ctx, req, NUM_REPUTER_RETRIES, NUM_REPUTER_RETRY_MIN_DELAY, NUM_REPUTER_RETRY_MAX_DELAY, "Send Reputer Leader Data")
+		ap.sendBulkPayload(ctx, payload, NUM_REPUTER_RETRIES, NUM_REPUTER_RETRY_MIN_DELAY, NUM_REPUTER_RETRY_MAX_DELAY)
 	}()
 }
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/allora-network/b7s/api"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// Phases of an asynchronous execution. b7s runs the roll call and the execution on the workers in
+// one call, so the roll call phase lasts until the workers' results are aggregated. The head does not
+// see the leader broadcast its transaction, so the submitted phase comes once the transaction is
+// found on chain, right before the confirmed phase.
+const (
+	AsyncPhaseRollCall   = "roll_call"
+	AsyncPhaseAggregated = "aggregated"
+	AsyncPhaseSubmitted  = "submitted"
+	AsyncPhaseConfirmed  = "confirmed"
+	AsyncPhaseFailed     = "failed"
+)
+
+const (
+	defaultAsyncJobRetention = time.Hour
+	asyncCallbackTimeout     = 10 * time.Second
+	asyncCallbackRetries     = 3
+	asyncCallbackMinDelay    = time.Second
+	asyncCallbackLookupTime  = 5 * time.Second
+)
+
+// Networks callbacks are never posted to unless allowed, besides loopback, private, link-local,
+// multicast and unspecified addresses: shared, benchmarking and "this network" addresses.
+var reservedCallbackNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("198.18.0.0/15"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// AsyncExecuteRequest describes the payload for the REST API request for asynchronous function execution.
+type AsyncExecuteRequest struct {
+	ExecuteRequest
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// AsyncJob is the state of an asynchronous execution, as polled by clients and posted to their callback.
+type AsyncJob struct {
+	ID         string           `json:"id"`
+	Phase      string           `json:"phase"`
+	Done       bool             `json:"done"`
+	Topic      string           `json:"topic"`
+	FunctionID string           `json:"function_id"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	Response   *ExecuteResponse `json:"response,omitempty"`
+	Submission *ChainSubmission `json:"submission,omitempty"`
+}
+
+type asyncJobEntry struct {
+	job AsyncJob
+	// API client which created the job, empty without authentication.
+	owner string
+	// States to post to the callback, in phase order. Nil without callback.
+	callbacks chan AsyncJob
+}
+
+// AsyncJobs keeps the asynchronous executions of the head. Jobs are dropped once done for longer
+// than the retention.
+type AsyncJobs struct {
+	lock      sync.Mutex
+	jobs      map[string]*asyncJobEntry
+	retention time.Duration
+	callbacks *CallbackPolicy
+	client    *http.Client
+	log       zerolog.Logger
+}
+
+// NewAsyncJobs creates an empty job registry, posting callbacks to the addresses the policy allows.
+func NewAsyncJobs(retention time.Duration, callbacks *CallbackPolicy, log zerolog.Logger) *AsyncJobs {
+	return &AsyncJobs{
+		jobs:      make(map[string]*asyncJobEntry),
+		retention: retention,
+		callbacks: callbacks,
+		client:    callbacks.client(),
+		log:       log,
+	}
+}
+
+func (j *AsyncJobs) create(req AsyncExecuteRequest, owner string) AsyncJob {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	now := time.Now()
+	for id, entry := range j.jobs {
+		if entry.job.Done && now.Sub(entry.job.UpdatedAt) > j.retention {
+			delete(j.jobs, id)
+		}
+	}
+
+	entry := &asyncJobEntry{
+		job: AsyncJob{
+			ID:         uuid.NewString(),
+			Phase:      AsyncPhaseRollCall,
+			Topic:      req.Topic,
+			FunctionID: req.FunctionID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		},
+		owner: owner,
+	}
+	if req.CallbackURL != "" {
+		// Room for every phase, so that updates never wait for the callback.
+		entry.callbacks = make(chan AsyncJob, 8)
+		go j.deliver(req.CallbackURL, entry.callbacks)
+	}
+	j.jobs[entry.job.ID] = entry
+	return entry.job
+}
+
+// Get returns the current state of the job, if created by the API client.
+func (j *AsyncJobs) Get(id string, owner string) (AsyncJob, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	entry, ok := j.jobs[id]
+	if !ok || entry.owner != owner {
+		return AsyncJob{}, false
+	}
+	return entry.job, true
+}
+
+// update applies fn to the job and posts the new state to the job's callback, if any.
+func (j *AsyncJobs) update(id string, fn func(job *AsyncJob)) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	entry, ok := j.jobs[id]
+	if !ok || entry.job.Done {
+		return
+	}
+	fn(&entry.job)
+	entry.job.UpdatedAt = time.Now()
+	if entry.callbacks != nil {
+		entry.callbacks <- entry.job
+		if entry.job.Done {
+			close(entry.callbacks)
+		}
+	}
+}
+
+// deliver posts the job states to the callback URL one after another.
+func (j *AsyncJobs) deliver(callbackURL string, states <-chan AsyncJob) {
+	for state := range states {
+		j.callback(callbackURL, state)
+	}
+}
+
+// callback posts the job state to the callback URL, retrying with backoff on failure.
+func (j *AsyncJobs) callback(callbackURL string, job AsyncJob) {
+	log := j.log.With().Str("job", job.ID).Str("phase", job.Phase).Logger()
+	body, err := json.Marshal(job)
+	if err != nil {
+		log.Error().Err(err).Msg("could not encode async job callback")
+		return
+	}
+
+	delay := asyncCallbackMinDelay
+	for attempt := 1; attempt <= asyncCallbackRetries; attempt++ {
+		err = j.post(callbackURL, body)
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).Int("attempt", attempt).Msg("could not post async job callback")
+		if attempt < asyncCallbackRetries {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+}
+
+func (j *AsyncJobs) post(callbackURL string, body []byte) error {
+	res, err := j.client.Post(callbackURL, echo.MIMEApplicationJSON, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("callback responded with status %s", res.Status)
+	}
+	return nil
+}
+
+// run executes the request of the job and follows it through aggregation and chain submission.
+func (j *AsyncJobs) run(ctx context.Context, a api.API, history *HistoryStore, id string, req ExecuteRequest) {
+	followExecution(ctx, a, history, req, func(p ExecutionProgress) {
+		// Jobs start in the roll call phase.
+		if p.Phase == AsyncPhaseRollCall {
+			return
+		}
+		j.update(id, func(job *AsyncJob) {
+			job.Phase = p.Phase
+			job.Done = p.Done
+			if p.Response != nil {
+				job.Response = p.Response
+			}
+			if p.Submission != nil {
+				job.Submission = p.Submission
+			}
+		})
+	})
+}
+
+// ExecutionProgress is a phase of an execution followed through the submission of its results.
+type ExecutionProgress struct {
+	Phase      string
+	Done       bool
+	Response   *ExecuteResponse
+	Submission *ChainSubmission
+}
+
+// followExecution executes the request and reports its phases in order, the last one done. It
+// returns once the results are aggregated, the submission phases are reported later on.
+func followExecution(ctx context.Context, a api.API, history *HistoryStore, req ExecuteRequest, report func(ExecutionProgress)) {
+	report(ExecutionProgress{Phase: AsyncPhaseRollCall})
+
+	// Submission updates wait for the aggregated phase to be reported first.
+	aggregated := make(chan struct{})
+	onSubmission := func(sub ChainSubmission) {
+		<-aggregated
+		if sub.Status == SubmissionConfirmed {
+			report(ExecutionProgress{Phase: AsyncPhaseSubmitted, Submission: &sub})
+		}
+		report(ExecutionProgress{Phase: asyncSubmissionPhase(sub), Submission: &sub, Done: true})
+	}
+
+	res, tracked := executeRequest(ctx, a, history, req, onSubmission)
+
+	progress := ExecutionProgress{Phase: AsyncPhaseAggregated, Response: &res, Done: !tracked}
+	if res.Error != nil {
+		progress.Phase = AsyncPhaseFailed
+	}
+	if tracked {
+		progress.Submission = &ChainSubmission{Status: SubmissionPending, UpdatedAt: time.Now()}
+	}
+	report(progress)
+	close(aggregated)
+}
+
+// asyncSubmissionPhase returns the final phase of a job given the outcome of its chain submission.
+func asyncSubmissionPhase(sub ChainSubmission) string {
+	switch sub.Status {
+	case SubmissionConfirmed:
+		return AsyncPhaseConfirmed
+	case SubmissionPending:
+		return AsyncPhaseAggregated
+	default:
+		return AsyncPhaseFailed
+	}
+}
+
+// CallbackPolicy restricts the addresses callbacks are posted to. Loopback, private, link-local and
+// other non-public addresses are refused unless in one of the allowed networks, both when a job is
+// created and when connecting, so that a host resolving to another address later is refused too.
+type CallbackPolicy struct {
+	allowed []*net.IPNet
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewCallbackPolicy creates a callback policy allowing the networks, given in CIDR notation, on top
+// of the public addresses.
+func NewCallbackPolicy(allowedNetworks []string) (*CallbackPolicy, error) {
+	policy := &CallbackPolicy{lookup: net.DefaultResolver.LookupIPAddr}
+	for _, cidr := range allowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid callback network %q: %w", cidr, err)
+		}
+		policy.allowed = append(policy.allowed, network)
+	}
+	return policy, nil
+}
+
+// allows returns whether callbacks can be posted to the address.
+func (p *CallbackPolicy) allows(ip net.IP) bool {
+	for _, network := range p.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range reservedCallbackNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that the callback is an absolute http(s) URL whose host only resolves to allowed
+// addresses.
+func (p *CallbackPolicy) Validate(ctx context.Context, callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("callback URL must be an absolute http or https URL")
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		ips = append(ips, ip)
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, asyncCallbackLookupTime)
+		defer cancel()
+		addrs, err := p.lookup(ctx, u.Hostname())
+		if err != nil {
+			return fmt.Errorf("could not resolve callback host %s: %w", u.Hostname(), err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if !p.allows(ip) {
+			return fmt.Errorf("callback host %s resolves to %s, which is not an allowed address", u.Hostname(), ip)
+		}
+	}
+	return nil
+}
+
+// client returns an HTTP client which only connects to allowed addresses, checked after resolution.
+// Proxies are not used, since they would connect on behalf of the client.
+func (p *CallbackPolicy) client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: asyncCallbackTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !p.allows(ip) {
+				return fmt.Errorf("callback address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   asyncCallbackTimeout,
+		Transport: &http.Transport{Proxy: nil, DialContext: dialer.DialContext},
+	}
+}
+
+// createAsyncExecutor starts the execution of the request in the background and responds with the
+// job to poll, right away.
+func createAsyncExecutor(a api.API, history *HistoryStore, jobs *AsyncJobs, admission *Admission) func(ctx echo.Context) error {
+
+	return func(ctx echo.Context) error {
+
+		// Unpack the API request.
+		var req AsyncExecuteRequest
+		err := ctx.Bind(&req)
+		if err != nil {
+			return badRequest(fmt.Sprintf("could not unpack request: %s", err))
+		}
+		err = jobs.callbacks.Validate(ctx.Request().Context(), req.CallbackURL)
+		if err != nil {
+			return badRequest(err.Error())
+		}
+
+		// The slot is taken before responding, so that a busy head rejects the request.
+		release, err := admission.Acquire(ctx.Request().Context())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		}
+
+		owner, _ := ctx.Get(contextKeyAPIClient).(string)
+		job := jobs.create(req, owner)
+		// The execution outlives the HTTP request, but stays in its trace.
+		go func() {
+			defer release()
+			jobs.run(context.WithoutCancel(ctx.Request().Context()), a, history, job.ID, req.ExecuteRequest)
+		}()
+
+		return ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+// getAsyncJob serves the state of an asynchronous execution to the API client which created it.
+// Jobs of other clients are not found.
+func getAsyncJob(jobs *AsyncJobs) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		owner, _ := ctx.Get(contextKeyAPIClient).(string)
+		job, ok := jobs.Get(ctx.Param("id"), owner)
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, "job not found")
+		}
+		return ctx.JSON(http.StatusOK, job)
+	}
+}
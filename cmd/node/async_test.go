@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncJobs(t *testing.T) {
+	posted := make(chan AsyncJob, 8)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var job AsyncJob
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&job))
+		posted <- job
+	}))
+	defer callback.Close()
+
+	// The test server listens on loopback, which callbacks are only posted to when allowed.
+	refused, err := NewCallbackPolicy(nil)
+	require.NoError(t, err)
+	require.Error(t, refused.Validate(context.Background(), callback.URL))
+	_, err = refused.client().Post(callback.URL, echo.MIMEApplicationJSON, nil)
+	require.ErrorContains(t, err, "not allowed")
+
+	callbacks, err := NewCallbackPolicy([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	require.NoError(t, callbacks.Validate(context.Background(), callback.URL))
+	jobs := NewAsyncJobs(time.Hour, callbacks, zerolog.Nop())
+	job := jobs.create(AsyncExecuteRequest{ExecuteRequest: ExecuteRequest{Topic: "1"}, CallbackURL: callback.URL}, "scheduler")
+	require.Equal(t, AsyncPhaseRollCall, job.Phase)
+
+	sub := ChainSubmission{Status: SubmissionConfirmed, TxHashes: []string{"ABC"}}
+	for _, phase := range []string{AsyncPhaseAggregated, AsyncPhaseSubmitted} {
+		jobs.update(job.ID, func(job *AsyncJob) { job.Phase = phase })
+	}
+	jobs.update(job.ID, func(job *AsyncJob) {
+		job.Phase = asyncSubmissionPhase(sub)
+		job.Submission = &sub
+		job.Done = true
+	})
+	// Done jobs do not change anymore.
+	jobs.update(job.ID, func(job *AsyncJob) { job.Phase = AsyncPhaseFailed })
+
+	polled, ok := jobs.Get(job.ID, "scheduler")
+	require.True(t, ok)
+	require.True(t, polled.Done)
+	require.Equal(t, AsyncPhaseConfirmed, polled.Phase)
+
+	// Callbacks are posted in phase order.
+	for _, phase := range []string{AsyncPhaseAggregated, AsyncPhaseSubmitted, AsyncPhaseConfirmed} {
+		select {
+		case job := <-posted:
+			require.Equal(t, phase, job.Phase)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no callback for phase %s", phase)
+		}
+	}
+
+	_, ok = jobs.Get("unknown", "scheduler")
+	require.False(t, ok)
+	// Jobs are only visible to the client which created them.
+	_, ok = jobs.Get(job.ID, "other")
+	require.False(t, ok)
+	_, ok = jobs.Get(job.ID, "")
+	require.False(t, ok)
+}
+
+func TestCallbackPolicy(t *testing.T) {
+	policy, err := NewCallbackPolicy([]string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	hosts := map[string][]net.IPAddr{
+		"scheduler.example.com": {{IP: net.ParseIP("93.184.216.34")}},
+		"internal.example.com":  {{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("192.168.1.10")}},
+	}
+	policy.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		addrs, ok := hosts[host]
+		if !ok {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		return addrs, nil
+	}
+	ctx := context.Background()
+
+	require.NoError(t, policy.Validate(ctx, ""))
+	require.NoError(t, policy.Validate(ctx, "https://scheduler.example.com/callback"))
+	require.NoError(t, policy.Validate(ctx, "http://10.1.2.3:8080/callback"))
+	for _, callbackURL := range []string{
+		"/callback",
+		"file:///etc/passwd",
+		"https://unknown.example.com/callback",
+		"https://internal.example.com/callback",
+		"http://127.0.0.1/callback",
+		"http://localhost.:8080/callback",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.2.0.1/callback",
+		"http://[::1]/callback",
+		"http://[fd00::1]/callback",
+		"http://[::ffff:127.0.0.1]/callback",
+		"http://0.0.0.0/callback",
+		"http://100.64.0.1/callback",
+	} {
+		require.Error(t, policy.Validate(ctx, callbackURL), callbackURL)
+	}
+
+	_, err = NewCallbackPolicy([]string{"10.0.0.0"})
+	require.Error(t, err)
+}
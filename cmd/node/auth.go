@@ -0,0 +1,364 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/labstack/echo/v4"
+)
+
+// Permissions of API clients.
+const (
+	PermissionExecute = "execute" // execute functions
+	PermissionInstall = "install" // install functions
+	PermissionRead    = "read"    // read execution results, history and jobs
+)
+
+// Signed requests may be this far off the head's clock.
+const maxSignatureClockSkew = 5 * time.Minute
+
+// Longest nonce of a signed request.
+const maxSignatureNonceLength = 64
+
+// Nonces of signed requests kept per client. Each is kept until its request expires, so this bounds
+// the signed requests a client can send in that time.
+const maxSignatureNoncesPerClient = 10000
+
+// Key of the name of the authenticated client in the echo context.
+const contextKeyAPIClient = "api_client"
+
+var (
+	errUnauthenticated = errors.New("missing or invalid credentials")
+	errUnknownClient   = errors.New("unknown client")
+	errTooManyNonces   = errors.New("too many signed requests in flight")
+)
+
+// APIClient is a client of the head REST API, identified by an API key, an HMAC secret or the
+// common name of its TLS client certificate.
+type APIClient struct {
+	Name           string   `json:"name"`
+	APIKey         Secret   `json:"api_key,omitempty"`
+	HMACSecret     Secret   `json:"hmac_secret,omitempty"`
+	CertCommonName string   `json:"cert_common_name,omitempty"`
+	Permissions    []string `json:"permissions"`
+	Topics         []string `json:"topics,omitempty"` // topic IDs the client may execute on, all if empty
+}
+
+func (c *APIClient) allows(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsTopic tells whether the client may execute on the topic, in any worker mode.
+func (c *APIClient) allowsTopic(alloraTopic string) bool {
+	if len(c.Topics) == 0 {
+		return true
+	}
+	topic, err := ParseRequestTopic(alloraTopic)
+	if err != nil {
+		return false
+	}
+	for _, t := range c.Topics {
+		if t == topic.IDString() {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks that the client has the permission and, for executions, may execute on the topics.
+func (c *APIClient) authorize(permission string, topics []string) error {
+	if !c.allows(permission) {
+		return fmt.Errorf("client %s lacks the %s permission", c.Name, permission)
+	}
+	if permission != PermissionExecute {
+		return nil
+	}
+	for _, topic := range topics {
+		if !c.allowsTopic(topic) {
+			return fmt.Errorf("client %s may not execute on topic %q", c.Name, topic)
+		}
+	}
+	return nil
+}
+
+// APIAuthConfig is the content of the API auth config file.
+type APIAuthConfig struct {
+	Clients []APIClient `json:"clients"`
+}
+
+// APIAuth authenticates and authorizes the requests to the head REST API. A nil APIAuth lets every
+// request through.
+type APIAuth struct {
+	clients []APIClient
+	now     func() time.Time
+
+	// Nonces of the signed requests seen by client, with the time they can be forgotten at, once
+	// their timestamp is too far off to be accepted again.
+	lock   sync.Mutex
+	nonces map[string]map[string]time.Time
+}
+
+// LoadAPIAuth reads the API clients from a JSON config file.
+func LoadAPIAuth(path string) (*APIAuth, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg APIAuthConfig
+	err = json.Unmarshal(content, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse API auth config: %w", err)
+	}
+	return NewAPIAuth(cfg.Clients)
+}
+
+// NewAPIAuth checks the clients and creates an APIAuth for them.
+func NewAPIAuth(clients []APIClient) (*APIAuth, error) {
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, c := range clients {
+		if c.Name == "" {
+			return nil, errors.New("API client without name")
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("duplicate API client %s", c.Name)
+		}
+		names[c.Name] = true
+		if c.APIKey.Empty() && c.HMACSecret.Empty() && c.CertCommonName == "" {
+			return nil, fmt.Errorf("API client %s has no api_key, hmac_secret or cert_common_name", c.Name)
+		}
+		if key := c.APIKey.Reveal(); key != "" {
+			if keys[key] {
+				return nil, fmt.Errorf("API client %s reuses the API key of another client", c.Name)
+			}
+			keys[key] = true
+		}
+		for _, p := range c.Permissions {
+			if p != PermissionExecute && p != PermissionInstall && p != PermissionRead {
+				return nil, fmt.Errorf("unknown permission %q of API client %s, expected %s, %s or %s", p, c.Name, PermissionExecute, PermissionInstall, PermissionRead)
+			}
+		}
+	}
+	return &APIAuth{clients: clients, now: time.Now, nonces: make(map[string]map[string]time.Time)}, nil
+}
+
+// Topics returns the topic IDs of the client allowlists.
+func (a *APIAuth) Topics() []string {
+	if a == nil {
+		return nil
+	}
+	var topics []string
+	for _, c := range a.clients {
+		topics = append(topics, c.Topics...)
+	}
+	return topics
+}
+
+// Secrets returns the API keys and HMAC secrets of the clients, to keep them out of the logs.
+func (a *APIAuth) Secrets() []Secret {
+	if a == nil {
+		return nil
+	}
+	var secrets []Secret
+	for _, c := range a.clients {
+		secrets = append(secrets, c.APIKey, c.HMACSecret)
+	}
+	return secrets
+}
+
+// Require returns a middleware letting through the requests of authenticated clients having the
+// permission. Execution requests must also be for topics the client is allowed to execute on.
+func (a *APIAuth) Require(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if a == nil {
+			return next
+		}
+
+		return func(ctx echo.Context) error {
+			body, err := readRequestBody(ctx)
+			if err != nil {
+				return err
+			}
+
+			client, err := a.authenticate(ctx.Request(), body)
+			if errors.Is(err, errTooManyNonces) {
+				return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			err = client.authorize(permission, requestTopics(ctx.Path(), body))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
+
+			ctx.Set(contextKeyAPIClient, client.Name)
+			return next(ctx)
+		}
+	}
+}
+
+// authenticate finds the client of a request by its TLS client certificate, HMAC signature or API key.
+func (a *APIAuth) authenticate(req *http.Request, body []byte) (*APIClient, error) {
+	if client := a.clientByCert(req.TLS); client != nil {
+		return client, nil
+	}
+	if signature := req.Header.Get(headapi.HeaderSignature); signature != "" {
+		return a.authenticateSignature(req, body, signature)
+	}
+	return a.clientByKey(req.Header.Get(echo.HeaderAuthorization), req.Header.Get(headapi.HeaderAPIKey))
+}
+
+// clientByCert returns the client of the verified TLS client certificate, if any.
+func (a *APIAuth) clientByCert(state *tls.ConnectionState) *APIClient {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	commonName := state.VerifiedChains[0][0].Subject.CommonName
+	for i := range a.clients {
+		if a.clients[i].CertCommonName != "" && a.clients[i].CertCommonName == commonName {
+			return &a.clients[i]
+		}
+	}
+	return nil
+}
+
+// clientByKey returns the client of the API key given as bearer token or on its own.
+func (a *APIAuth) clientByKey(authorization string, apiKey string) (*APIClient, error) {
+	if bearer, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		apiKey = bearer
+	}
+	if apiKey != "" {
+		for i := range a.clients {
+			if !a.clients[i].APIKey.Empty() && secureEqual(apiKey, a.clients[i].APIKey.Reveal()) {
+				return &a.clients[i], nil
+			}
+		}
+	}
+	return nil, errUnauthenticated
+}
+
+func (a *APIAuth) authenticateSignature(req *http.Request, body []byte, signature string) (*APIClient, error) {
+	var client *APIClient
+	name := req.Header.Get(headapi.HeaderClient)
+	for i := range a.clients {
+		if a.clients[i].Name == name && !a.clients[i].HMACSecret.Empty() {
+			client = &a.clients[i]
+		}
+	}
+	if client == nil {
+		return nil, errUnknownClient
+	}
+
+	timestamp := req.Header.Get(headapi.HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid signature timestamp")
+	}
+	now := a.now()
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew > maxSignatureClockSkew || skew < -maxSignatureClockSkew {
+		return nil, errors.New("signature timestamp is too far off")
+	}
+	nonce := req.Header.Get(headapi.HeaderNonce)
+	if nonce == "" || len(nonce) > maxSignatureNonceLength {
+		return nil, errors.New("missing or invalid signature nonce")
+	}
+
+	expected := headapi.SignRequest(client.HMACSecret.Reveal(), req.Method, req.URL.RequestURI(), timestamp, nonce, body)
+	if !secureEqual(strings.ToLower(signature), expected) {
+		return nil, errUnauthenticated
+	}
+	err = a.useNonce(client.Name, nonce, time.Unix(unix, 0).Add(maxSignatureClockSkew), now)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// useNonce records the nonce of a signed request of the client, kept until its request expires. It
+// fails if the nonce was already used, or if the client has too many nonces kept.
+func (a *APIAuth) useNonce(client string, nonce string, expires time.Time, now time.Time) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	nonces := a.nonces[client]
+	if nonces == nil {
+		nonces = make(map[string]time.Time)
+		a.nonces[client] = nonces
+	}
+	if forget, ok := nonces[nonce]; ok && !now.After(forget) {
+		return errors.New("signature nonce was already used")
+	}
+	if len(nonces) >= maxSignatureNoncesPerClient {
+		for key, forget := range nonces {
+			if now.After(forget) {
+				delete(nonces, key)
+			}
+		}
+		if len(nonces) >= maxSignatureNoncesPerClient {
+			return fmt.Errorf("%w, at most %d per %s", errTooManyNonces, maxSignatureNoncesPerClient, 2*maxSignatureClockSkew)
+		}
+	}
+	nonces[nonce] = expires
+	return nil
+}
+
+// requestTopics returns the topics of an execution request body, decoded as the request of its
+// route like the handler does: a batch for the batch route, a single execution otherwise.
+func requestTopics(path string, body []byte) []string {
+	// Malformed bodies are rejected by the handler, the topic checks see no topic.
+	if path == headapi.PathExecuteBatch {
+		var batch BatchExecuteRequest
+		_ = json.Unmarshal(body, &batch)
+		topics := make([]string, len(batch.Requests))
+		for i, r := range batch.Requests {
+			topics[i] = r.Topic
+		}
+		return topics
+	}
+	var req ExecuteRequest
+	_ = json.Unmarshal(body, &req)
+	return []string{req.Topic}
+}
+
+// apiTLSConfig returns the TLS config of the REST API. Client certificates signed by the client
+// CA are verified if given, clients without one can still use the other methods.
+func apiTLSConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load REST API TLS certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read REST API client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in REST API client CA")
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return cfg, nil
+}
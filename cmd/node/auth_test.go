@@ -0,0 +1,151 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIAuth(t *testing.T) {
+	auth, err := NewAPIAuth([]APIClient{
+		{Name: "reader", APIKey: NewSecret([]byte("read-key")), Permissions: []string{PermissionRead}},
+		{Name: "executor", APIKey: NewSecret([]byte("exec-key")), Permissions: []string{PermissionExecute}, Topics: []string{"1"}},
+		{Name: "signer", HMACSecret: NewSecret([]byte("hmac-secret")), Permissions: []string{PermissionExecute}},
+	})
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	auth.now = func() time.Time { return now }
+
+	e := echo.New()
+	statusAt := func(path string, permission string, body string, setup func(r *http.Request)) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		setup(r)
+		w := httptest.NewRecorder()
+		handler := auth.Require(permission)(func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusOK)
+		})
+		ctx := e.NewContext(r, w)
+		ctx.SetPath(path)
+		err := handler(ctx)
+		if he, ok := err.(*echo.HTTPError); ok {
+			return he.Code
+		}
+		return w.Code
+	}
+	status := func(permission string, body string, setup func(r *http.Request)) int {
+		return statusAt(headapi.PathExecute, permission, body, setup)
+	}
+	apiKey := func(key string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set(echo.HeaderAuthorization, "Bearer "+key) }
+	}
+	signedWith := func(secret string, at time.Time, nonce string, body string) func(r *http.Request) {
+		return func(r *http.Request) {
+			timestamp := strconv.FormatInt(at.Unix(), 10)
+			r.Header.Set(headapi.HeaderClient, "signer")
+			r.Header.Set(headapi.HeaderTimestamp, timestamp)
+			r.Header.Set(headapi.HeaderNonce, nonce)
+			r.Header.Set(headapi.HeaderSignature, headapi.SignRequest(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, []byte(body)))
+		}
+	}
+	signed := func(secret string, at time.Time, body string) func(r *http.Request) {
+		return signedWith(secret, at, headapi.NewNonce(), body)
+	}
+
+	require.Equal(t, http.StatusUnauthorized, status(PermissionRead, "", func(r *http.Request) {}))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionRead, "", apiKey("wrong")))
+	require.Equal(t, http.StatusOK, status(PermissionRead, "", apiKey("read-key")))
+	require.Equal(t, http.StatusOK, status(PermissionRead, "", func(r *http.Request) { r.Header.Set(headapi.HeaderAPIKey, "read-key") }))
+	require.Equal(t, http.StatusForbidden, status(PermissionExecute, `{"topic":"1"}`, apiKey("read-key")))
+
+	// Topic allowlist, in every worker mode and for every request of a batch.
+	require.Equal(t, http.StatusOK, status(PermissionExecute, `{"topic":"1"}`, apiKey("exec-key")))
+	require.Equal(t, http.StatusOK, status(PermissionExecute, `{"topic":"1/reputer"}`, apiKey("exec-key")))
+	require.Equal(t, http.StatusForbidden, status(PermissionExecute, `{"topic":"2"}`, apiKey("exec-key")))
+	require.Equal(t, http.StatusForbidden, statusAt(headapi.PathExecuteBatch, PermissionExecute, `{"requests":[{"topic":"1"},{"topic":"2"}]}`, apiKey("exec-key")))
+	require.Equal(t, http.StatusOK, statusAt(headapi.PathExecuteBatch, PermissionExecute, `{"requests":[{"topic":"1"},{"topic":"1/reputer"}]}`, apiKey("exec-key")))
+
+	// Topics are those of the request the route runs, whatever other keys the body has.
+	mixed := `{"function_id":"bafy","method":"main.wasm","topic":"2","requests":[{"topic":"1"}]}`
+	require.Equal(t, http.StatusForbidden, status(PermissionExecute, mixed, apiKey("exec-key")))
+	require.Equal(t, http.StatusForbidden, statusAt(headapi.PathExecuteAsync, PermissionExecute, mixed, apiKey("exec-key")))
+	require.Equal(t, http.StatusOK, statusAt(headapi.PathExecuteBatch, PermissionExecute, mixed, apiKey("exec-key")))
+	mixed = `{"topic":"1","requests":[{"topic":"2"}]}`
+	require.Equal(t, http.StatusOK, status(PermissionExecute, mixed, apiKey("exec-key")))
+	require.Equal(t, http.StatusForbidden, statusAt(headapi.PathExecuteBatch, PermissionExecute, mixed, apiKey("exec-key")))
+
+	// HMAC signatures cover the body and expire.
+	body := `{"topic":"2"}`
+	require.Equal(t, http.StatusOK, status(PermissionExecute, body, signed("hmac-secret", now, body)))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signed("wrong", now, body)))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signed("hmac-secret", now, `{"topic":"1"}`)))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signed("hmac-secret", now.Add(-time.Hour), body)))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signedWith("hmac-secret", now, "", body)))
+
+	// Signed requests cannot be replayed while their timestamp is accepted.
+	require.Equal(t, http.StatusOK, status(PermissionExecute, body, signedWith("hmac-secret", now, "nonce", body)))
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signedWith("hmac-secret", now, "nonce", body)))
+	now = now.Add(maxSignatureClockSkew)
+	require.Equal(t, http.StatusUnauthorized, status(PermissionExecute, body, signedWith("hmac-secret", now.Add(-maxSignatureClockSkew), "nonce", body)))
+
+	// Nonces are capped per client until their requests expire.
+	for i := len(auth.nonces["signer"]); i < maxSignatureNoncesPerClient; i++ {
+		auth.nonces["signer"][strconv.Itoa(i)] = now.Add(maxSignatureClockSkew)
+	}
+	require.Equal(t, http.StatusTooManyRequests, status(PermissionExecute, body, signed("hmac-secret", now, body)))
+	now = now.Add(maxSignatureClockSkew + time.Second)
+	require.Equal(t, http.StatusOK, status(PermissionExecute, body, signed("hmac-secret", now, body)))
+	require.Len(t, auth.nonces["signer"], 1)
+
+	// Without config every request goes through.
+	var open *APIAuth
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/executions", nil)
+	w := httptest.NewRecorder()
+	err = open.Require(PermissionRead)(func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })(e.NewContext(r, w))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNewAPIAuth(t *testing.T) {
+	_, err := NewAPIAuth([]APIClient{{Name: "nocredentials", Permissions: []string{PermissionRead}}})
+	require.Error(t, err)
+	_, err = NewAPIAuth([]APIClient{{Name: "a", CertCommonName: "a", Permissions: []string{"admin"}}})
+	require.Error(t, err)
+	_, err = NewAPIAuth([]APIClient{
+		{Name: "a", APIKey: NewSecret([]byte("key"))},
+		{Name: "b", APIKey: NewSecret([]byte("key"))},
+	})
+	require.Error(t, err)
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	auth, err := NewAPIAuth([]APIClient{{Name: "executor", APIKey: NewSecret([]byte("exec-key")), Permissions: []string{PermissionExecute}}})
+	require.NoError(t, err)
+	e := echo.New()
+	handler := limitRequestBody(16)(auth.Require(PermissionExecute)(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}))
+
+	status := func(body string, unknownLength bool) int {
+		r := httptest.NewRequest(http.MethodPost, headapi.PathExecute, strings.NewReader(body))
+		if unknownLength {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		err := handler(e.NewContext(r, w))
+		if he, ok := err.(*echo.HTTPError); ok {
+			return he.Code
+		}
+		return w.Code
+	}
+	// Bodies are bounded before authentication, whether their length is announced or not.
+	require.Equal(t, http.StatusUnauthorized, status(`{"topic":"1"}`, false))
+	require.Equal(t, http.StatusRequestEntityTooLarge, status(`{"topic":"1","method":"main.wasm"}`, false))
+	require.Equal(t, http.StatusRequestEntityTooLarge, status(`{"topic":"1","method":"main.wasm"}`, true))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/allora-network/b7s/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultBatchConcurrency = 8
+	defaultBatchMaxSize     = 100
+)
+
+// BatchExecuteRequest describes the payload for the REST API request for executing several functions at once.
+type BatchExecuteRequest struct {
+	Requests []ExecuteRequest `json:"requests"`
+}
+
+// BatchExecuteResponse describes the REST API response for a batch execution, with an item per
+// request in the order of the requests.
+type BatchExecuteResponse struct {
+	Items []BatchExecuteItem `json:"items"`
+}
+
+// BatchExecuteItem is the outcome of a single request of a batch. Error is set if the request was
+// invalid or its execution did not succeed.
+type BatchExecuteItem struct {
+	Index    int              `json:"index"`
+	Topic    string           `json:"topic,omitempty"`
+	Response *ExecuteResponse `json:"response,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// validateExecuteRequest checks what the head needs to route an execution request.
+func validateExecuteRequest(req ExecuteRequest) error {
+	if req.FunctionID == "" {
+		return errors.New("function_id is required")
+	}
+	if req.Method == "" {
+		return errors.New("method is required")
+	}
+	if req.Topic == "" {
+		return errors.New("topic is required")
+	}
+	_, err := ParseRequestTopic(req.Topic)
+	return err
+}
+
+// createBatchExecutor executes the requests of a batch concurrently, at most concurrency at a time,
+// and responds once all of them are done. Every request takes its own in-flight slot of the head.
+func createBatchExecutor(a api.API, history *HistoryStore, admission *Admission, concurrency int, maxSize int) func(ctx echo.Context) error {
+
+	return func(ctx echo.Context) error {
+
+		// Unpack the API request.
+		var req BatchExecuteRequest
+		err := ctx.Bind(&req)
+		if err != nil {
+			return badRequest(fmt.Sprintf("could not unpack request: %s", err))
+		}
+		if len(req.Requests) == 0 {
+			return badRequest("no requests in batch")
+		}
+		if len(req.Requests) > maxSize {
+			return badRequest(fmt.Sprintf("batch of %d requests is larger than the limit of %d", len(req.Requests), maxSize))
+		}
+
+		res := BatchExecuteResponse{Items: make([]BatchExecuteItem, len(req.Requests))}
+		slots := make(chan struct{}, max(concurrency, 1))
+		var wg sync.WaitGroup
+		for i, itemReq := range req.Requests {
+			item := &res.Items[i]
+			item.Index = i
+			item.Topic = itemReq.Topic
+			if err := validateExecuteRequest(itemReq); err != nil {
+				item.Error = err.Error()
+				continue
+			}
+
+			wg.Add(1)
+			go func(itemReq ExecuteRequest) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				release, err := admission.Acquire(ctx.Request().Context())
+				if err != nil {
+					item.Error = err.Error()
+					return
+				}
+				defer release()
+
+				itemRes, _ := executeRequest(ctx.Request().Context(), a, history, itemReq, nil)
+				item.Response = &itemRes
+				if itemRes.Error != nil {
+					item.Error = fmt.Sprintf("execution failed with code %s: %s", itemRes.Code, itemRes.Error.Message)
+				}
+			}(itemReq)
+		}
+		wg.Wait()
+
+		// Send the response.
+		return ctx.JSON(http.StatusOK, res)
+	}
+}
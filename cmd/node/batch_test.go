@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allora-network/b7s/api"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchExecutorValidation(t *testing.T) {
+	handler := createBatchExecutor(api.API{}, nil, nil, 2, 2)
+
+	call := func(body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/functions/execute/batch", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return rec, handler(echo.New().NewContext(req, rec))
+	}
+
+	_, err := call(`{"requests":[]}`)
+	require.Error(t, err)
+	_, err = call(`{"requests":[{},{},{}]}`)
+	require.Error(t, err)
+
+	// Invalid requests fail on their own, without failing the batch.
+	rec, err := call(`{"requests":[{"function_id":"f","method":"m.wasm"},{"topic":"1"}]}`)
+	require.NoError(t, err)
+	var res BatchExecuteResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	require.Len(t, res.Items, 2)
+	require.Equal(t, "topic is required", res.Items[0].Error)
+	require.Equal(t, 1, res.Items[1].Index)
+	require.Equal(t, "1", res.Items[1].Topic)
+	require.Equal(t, "function_id is required", res.Items[1].Error)
+}
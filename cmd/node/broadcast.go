@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errDryRun = errors.New("dry run, transactions are not broadcast")
+
+// Simulated gas can vary from the gas used by the real transaction, so some margin is added.
+const simulatedGasMargin = 20000
+
+// BroadcastTx signs msgs with the node account and broadcasts them through the endpoint pool.
+// Endpoints are tried in order of preference until one accepts the tx. From then on the
+// confirmation is polled on that same endpoint only, so the tx is never broadcast twice.
+func (ap *AppChain) BroadcastTx(ctx context.Context, msgs ...sdktypes.Msg) (txRes cosmosclient.Response, err error) {
+	if ap.Config.DryRun {
+		return cosmosclient.Response{}, errDryRun
+	}
+
+	topic, msgType := msgsTopic(msgs...), gasMsgType(msgs...)
+	ctx, span := tracer.Start(ctx, "chain.broadcast", trace.WithAttributes(attrTopic.String(topic), attribute.String("allora.msg_type", msgType)))
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		broadcastAttempts.WithLabelValues(topic, msgType, outcome).Inc()
+		if txRes.TxResponse != nil {
+			span.SetAttributes(attribute.String("allora.tx_hash", txRes.TxHash), attribute.Int64("allora.gas_used", txRes.GasUsed))
+		}
+		endSpan(span, err)
+	}()
+
+	err = errNoHealthyEndpoint
+	for _, ep := range ap.Endpoints.ordered() {
+		var res *sdktypes.TxResponse
+		var failover bool
+		res, failover, err = ap.broadcastOn(ctx, ep, msgs...)
+		if err == nil {
+			start := time.Now()
+			txRes, err = ap.waitForTx(ctx, ep, res.TxHash)
+			if txRes.TxResponse != nil && txRes.Height > 0 {
+				txConfirmationLatency.WithLabelValues(topic, msgType).Observe(time.Since(start).Seconds())
+				txGasUsed.WithLabelValues(topic, msgType).Observe(float64(txRes.GasUsed))
+			}
+			return txRes, err
+		}
+		if !failover || ctx.Err() != nil {
+			return cosmosclient.Response{}, err
+		}
+
+		ap.Endpoints.reportFailure(ep, err)
+		ap.Logger.Warn().Err(err).Str("endpoint", ep.address).Msg("could not broadcast tx on endpoint, failing over")
+	}
+	return cosmosclient.Response{}, err
+}
+
+// broadcastTx broadcasts the msgs with BroadcastTx, unless replaced for testing.
+func (ap *AppChain) broadcastTx(ctx context.Context, msgs ...sdktypes.Msg) (cosmosclient.Response, error) {
+	if ap.broadcast != nil {
+		return ap.broadcast(ctx, msgs...)
+	}
+	return ap.BroadcastTx(ctx, msgs...)
+}
+
+// broadcastOn builds, signs and broadcasts the tx on a single endpoint, without waiting for it to be
+// included in a block. The returned flag tells whether the failure is the endpoint's, so that
+// another endpoint may be tried.
+func (ap *AppChain) broadcastOn(ctx context.Context, ep *rpcEndpoint, msgs ...sdktypes.Msg) (*sdktypes.TxResponse, bool, error) {
+	clientCtx, txf, failover, err := ap.prepareTx(ep, msgs...)
+	if err != nil {
+		return nil, failover, err
+	}
+
+	plan, failover, err := ap.planTx(clientCtx, txf, msgs...)
+	if err != nil {
+		return nil, failover, err
+	}
+	txf = txf.WithGas(plan.Gas).WithFees(plan.Fees.String())
+
+	var height int64
+	if ap.Config.FeeBudget.BlockBased() {
+		height, err = ep.client.LatestBlockHeight(ctx)
+		if err != nil {
+			return nil, isTransportError(err), fmt.Errorf("could not get block height: %w", err)
+		}
+	}
+	release, err := ap.Config.FeeBudget.Reserve(plan.Fees, height)
+	if err != nil {
+		return nil, false, err
+	}
+	res, failover, err := ap.signAndBroadcast(ctx, clientCtx, txf, msgs...)
+	if err != nil {
+		release()
+		return nil, failover, err
+	}
+
+	// Fees are paid once the tx is accepted, even if it fails later on.
+	topic := msgsTopic(msgs...)
+	msgType := gasMsgType(msgs...)
+	for _, fee := range plan.Fees {
+		// Amounts may not fit in an int64.
+		amount, _ := new(big.Float).SetInt(fee.Amount.BigInt()).Float64()
+		feesSpent.WithLabelValues(topic, msgType, fee.Denom).Add(amount)
+	}
+	return res, false, nil
+}
+
+// signAndBroadcast signs the tx built by txf and broadcasts it, see broadcastOn for the returned flag.
+func (ap *AppChain) signAndBroadcast(ctx context.Context, clientCtx client.Context, txf tx.Factory, msgs ...sdktypes.Msg) (*sdktypes.TxResponse, bool, error) {
+	txBuilder, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, false, err
+	}
+	err = tx.Sign(ctx, txf, ap.Account.Name, txBuilder, true)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not sign tx: %w", err)
+	}
+	txBytes, err := clientCtx.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, err := clientCtx.BroadcastTxSync(txBytes)
+	if err != nil {
+		return nil, isTransportError(err), fmt.Errorf("could not broadcast tx: %w", err)
+	}
+	if res.Code != 0 {
+		return nil, false, txCodeError(res.Codespace, res.Code, fmt.Errorf("tx rejected with code %d: %s", res.Code, res.RawLog))
+	}
+	return res, false, nil
+}
+
+// TxPlan is the gas limit and fees msgs would be broadcast with.
+type TxPlan struct {
+	SimulatedGas uint64 // zero if the gas strategy does not simulate
+	Gas          uint64
+	Fees         sdktypes.Coins
+}
+
+// PlanTx works out the gas and fees of msgs sent from the node account, without broadcasting them.
+func (ap *AppChain) PlanTx(ctx context.Context, msgs ...sdktypes.Msg) (TxPlan, error) {
+	err := errNoHealthyEndpoint
+	for _, ep := range ap.Endpoints.ordered() {
+		var clientCtx client.Context
+		var txf tx.Factory
+		var plan TxPlan
+		var failover bool
+		clientCtx, txf, failover, err = ap.prepareTx(ep, msgs...)
+		if err == nil {
+			plan, failover, err = ap.planTx(clientCtx, txf, msgs...)
+			if err == nil {
+				return plan, nil
+			}
+		}
+		if !failover || ctx.Err() != nil {
+			return TxPlan{}, err
+		}
+		ap.Endpoints.reportFailure(ep, err)
+	}
+	return TxPlan{}, err
+}
+
+// planTx applies the gas strategy of the msgs and prices the resulting gas.
+func (ap *AppChain) planTx(clientCtx client.Context, txf tx.Factory, msgs ...sdktypes.Msg) (TxPlan, bool, error) {
+	strategy := ap.Config.GasStrategies.For(msgs...)
+
+	var plan TxPlan
+	if strategy.NeedsSimulation() {
+		sim, _, err := tx.CalculateGas(clientCtx, txf, msgs...)
+		if err != nil {
+			return TxPlan{}, isTransportError(err), fmt.Errorf("could not simulate tx: %w", err)
+		}
+		plan.SimulatedGas = sim.GasInfo.GasUsed
+	}
+
+	gas, err := strategy.GasLimit(plan.SimulatedGas)
+	if err != nil {
+		return TxPlan{}, false, err
+	}
+	if ap.Config.MaxTxGas > 0 && gas > ap.Config.MaxTxGas {
+		return TxPlan{}, false, fmt.Errorf("%w: tx needs %d gas, max is %d", errGasAboveLimit, gas, ap.Config.MaxTxGas)
+	}
+	plan.Gas = gas
+	plan.Fees = txFees(ap.Config.GasPrices, gas)
+	return plan, false, nil
+}
+
+// prepareTx validates msgs and sets up the client context and tx factory of the node account on the endpoint.
+func (ap *AppChain) prepareTx(ep *rpcEndpoint, msgs ...sdktypes.Msg) (client.Context, tx.Factory, bool, error) {
+	for _, msg := range msgs {
+		if m, ok := msg.(sdktypes.HasValidateBasic); ok {
+			if err := m.ValidateBasic(); err != nil {
+				return client.Context{}, tx.Factory{}, false, err
+			}
+		}
+	}
+
+	sdkAddress, err := ap.Account.Record.GetAddress()
+	if err != nil {
+		return client.Context{}, tx.Factory{}, false, err
+	}
+	clientCtx := ep.client.Context().
+		WithFromName(ap.Account.Name).
+		WithFromAddress(sdkAddress)
+
+	txf, err := ep.client.TxFactory.Prepare(clientCtx)
+	if err != nil {
+		return client.Context{}, tx.Factory{}, isTransportError(err), fmt.Errorf("could not prepare tx: %w", err)
+	}
+	return clientCtx, txf, false, nil
+}
+
+// waitForTx polls the endpoint that accepted the tx until it is included in a block.
+func (ap *AppChain) waitForTx(ctx context.Context, ep *rpcEndpoint, txHash string) (cosmosclient.Response, error) {
+	resultTx, err := ep.client.WaitForTx(ctx, txHash)
+	if err != nil {
+		return cosmosclient.Response{TxResponse: &sdktypes.TxResponse{TxHash: txHash}}, fmt.Errorf("could not confirm tx %s: %w", txHash, err)
+	}
+
+	res := cosmosclient.Response{
+		Codec:      ep.client.Context().Codec,
+		TxResponse: sdktypes.NewResponseResultTx(resultTx, nil, ""),
+	}
+	if res.Code != 0 {
+		return res, txCodeError(res.Codespace, res.Code, fmt.Errorf("tx %s failed with code %d: %s", txHash, res.Code, res.RawLog))
+	}
+	return res, nil
+}
+
+// txCodeError marks the error of a tx rejected or failed with the code as one of the emissions
+// errors that no retry can fix, if it is one.
+func txCodeError(codespace string, code uint32, err error) error {
+	if codespace == emissionstypes.ModuleName && code == emissionstypes.ErrNonceAlreadyFulfilled.ABCICode() {
+		return fmt.Errorf("%w: %w", emissionstypes.ErrNonceAlreadyFulfilled, err)
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultMaxTxBytes = 1048576
+	// Room left in a tx for everything but the msg: body, auth info, signature and fees.
+	txOverheadBytes = 2048
+	// Room left per bundle for its field tag and length prefix in the msg.
+	bundleOverheadBytes = 8
+)
+
+var errNoBundleFits = errors.New("no bundle fits in a transaction")
+
+// bulkPayload is a bulk insert msg for a topic nonce. The chain fulfills the nonce with the first
+// msg it accepts, so the bundles of a nonce are always sent in a single tx.
+type bulkPayload struct {
+	topicId uint64
+	nonce   int64
+	msgType string
+	sizes   []int                         // encoded size of each bundle
+	build   func(keep []int) sdktypes.Msg // msg carrying the bundles at the indices, in order
+}
+
+func workerBulkPayload(sender string, topicId uint64, nonce *emissionstypes.Nonce, bundles []*emissionstypes.WorkerDataBundle) bulkPayload {
+	sizes := make([]int, len(bundles))
+	for i, bundle := range bundles {
+		sizes[i] = bundle.Size()
+	}
+	return bulkPayload{
+		topicId: topicId,
+		nonce:   nonce.BlockHeight,
+		msgType: GasMsgTypeWorker,
+		sizes:   sizes,
+		build: func(keep []int) sdktypes.Msg {
+			kept := make([]*emissionstypes.WorkerDataBundle, len(keep))
+			for i, index := range keep {
+				kept[i] = bundles[index]
+			}
+			return &emissionstypes.MsgInsertBulkWorkerPayload{
+				Sender:            sender,
+				Nonce:             nonce,
+				TopicId:           topicId,
+				WorkerDataBundles: kept,
+			}
+		},
+	}
+}
+
+func reputerBulkPayload(sender string, topicId uint64, nonce *emissionstypes.ReputerRequestNonce, bundles []*emissionstypes.ReputerValueBundle) bulkPayload {
+	sizes := make([]int, len(bundles))
+	for i, bundle := range bundles {
+		sizes[i] = bundle.Size()
+	}
+	return bulkPayload{
+		topicId: topicId,
+		nonce:   nonce.ReputerNonce.BlockHeight,
+		msgType: GasMsgTypeReputer,
+		sizes:   sizes,
+		build: func(keep []int) sdktypes.Msg {
+			kept := make([]*emissionstypes.ReputerValueBundle, len(keep))
+			for i, index := range keep {
+				kept[i] = bundles[index]
+			}
+			return &emissionstypes.MsgInsertBulkReputerPayload{
+				Sender:              sender,
+				ReputerRequestNonce: nonce,
+				TopicId:             topicId,
+				ReputerValueBundles: kept,
+			}
+		},
+	}
+}
+
+// all returns the indices of every bundle of the payload.
+func (p bulkPayload) all() []int {
+	return keptBundles(p.sizes, 0)
+}
+
+// dropOrder returns the indices of the bundles in the order they are dropped to trim a payload:
+// the largest first, and the last received first among bundles of the same size.
+func dropOrder(sizes []int) []int {
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = len(sizes) - 1 - i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return sizes[order[i]] > sizes[order[j]] })
+	return order
+}
+
+// keptBundles returns the indices of the bundles left, in their original order, once the first
+// dropped bundles in drop order are dropped.
+func keptBundles(sizes []int, dropped int) []int {
+	drop := make(map[int]bool, dropped)
+	for _, index := range dropOrder(sizes)[:min(dropped, len(sizes))] {
+		drop[index] = true
+	}
+	kept := make([]int, 0, len(sizes)-len(drop))
+	for i := range sizes {
+		if !drop[i] {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+// trimBySize returns how many bundles must be dropped, in drop order, for the total size of the
+// rest to stay within limit.
+func trimBySize(sizes []int, limit int) int {
+	total := 0
+	for _, size := range sizes {
+		total += size + bundleOverheadBytes
+	}
+	dropped := 0
+	for _, index := range dropOrder(sizes) {
+		if total <= limit {
+			break
+		}
+		total -= sizes[index] + bundleOverheadBytes
+		dropped++
+	}
+	return dropped
+}
+
+// bulkSend is the outcome of sending a bulk payload.
+type bulkSend struct {
+	kept    []int // indices of the bundles sent
+	dropped int   // bundles left out to fit in the tx
+	txHash  string
+	err     error
+}
+
+// sendBulkPayload sends the bundles of the payload in a single tx. Bundles are dropped, largest
+// first, until the msg fits within the tx size limit, and while the tx needs more gas than allowed
+// half of the bundles left are dropped.
+func (ap *AppChain) sendBulkPayload(ctx context.Context, payload bulkPayload, maxRetries, minDelay, maxDelay int) bulkSend {
+	emptySize := proto.Size(payload.build(nil))
+	maxTxBytes := ap.Config.MaxTxBytes
+	if maxTxBytes <= 0 {
+		maxTxBytes = defaultMaxTxBytes
+	}
+	send := bulkSend{dropped: trimBySize(payload.sizes, maxTxBytes-txOverheadBytes-emptySize)}
+	if send.dropped > 0 {
+		ap.Logger.Warn().Uint64("topic", payload.topicId).Int64("nonce", payload.nonce).Str("msg_type", payload.msgType).
+			Int("bundles", len(payload.sizes)).Int("dropped", send.dropped).Msg("bulk payload is larger than a transaction, dropping the largest bundles")
+	}
+	send.kept = keptBundles(payload.sizes, send.dropped)
+
+	if ap.Config.DryRun {
+		ap.dryRunPayload(ctx, payload.topicId, payload.build(send.kept))
+		return send
+	}
+
+	for len(payload.sizes) > 0 {
+		if len(send.kept) == 0 {
+			send.err = errNoBundleFits
+			break
+		}
+		res, err := ap.SendDataWithRetry(ctx, payload.build(send.kept), maxRetries, minDelay, maxDelay, "Sent "+payload.msgType+" leader data")
+		if errors.Is(err, errGasAboveLimit) && len(send.kept) > 1 {
+			ap.Logger.Warn().Err(err).Uint64("topic", payload.topicId).Int64("nonce", payload.nonce).
+				Int("bundles", len(send.kept)).Msg("bulk payload needs too much gas, dropping the largest half of its bundles")
+			send.dropped += len(send.kept) / 2
+			send.kept = keptBundles(payload.sizes, send.dropped)
+			continue
+		}
+		if res != nil && res.TxResponse != nil {
+			send.txHash = res.TxHash
+		}
+		send.err = err
+		break
+	}
+
+	ap.reportBulkPayload(payload, send)
+	return send
+}
+
+// reportBulkPayload logs and counts how many bundles of the payload made it to the chain.
+func (ap *AppChain) reportBulkPayload(payload bulkPayload, send bulkSend) {
+	topic := strconv.FormatUint(payload.topicId, 10)
+
+	var submitted, failed int
+	if send.err != nil {
+		failed = len(send.kept)
+	} else {
+		submitted = len(send.kept)
+	}
+	bulkBundles.WithLabelValues(topic, payload.msgType, "submitted").Add(float64(submitted))
+	bulkBundles.WithLabelValues(topic, payload.msgType, "failed").Add(float64(failed))
+	bulkBundles.WithLabelValues(topic, payload.msgType, "dropped").Add(float64(send.dropped))
+
+	level, msg, outcome := zerolog.InfoLevel, "bulk payload sent", "success"
+	switch {
+	case submitted > 0 && send.dropped > 0:
+		level, msg, outcome = zerolog.WarnLevel, "bulk payload partially sent", "partial"
+	case submitted == 0 && (failed > 0 || send.dropped > 0):
+		level, msg, outcome = zerolog.ErrorLevel, "bulk payload could not be sent", "failure"
+	case submitted == 0:
+		level, msg, outcome = zerolog.WarnLevel, "bulk payload has no bundle to send", "empty"
+	}
+	if payload.msgType == GasMsgTypeReputer {
+		reputerChainCommit.WithLabelValues(topic, outcome).Inc()
+	} else {
+		workerChainCommit.WithLabelValues(topic, outcome).Inc()
+	}
+	ap.Logger.WithLevel(level).Err(send.err).Uint64("topic", payload.topicId).
+		Int64("nonce", payload.nonce).
+		Str("msg_type", payload.msgType).
+		Int("bundles_submitted", submitted).
+		Int("bundles_failed", failed).
+		Int("bundles_dropped", send.dropped).
+		Str("tx_hash", send.txHash).
+		Msg(msg)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimBySize(t *testing.T) {
+	// Every bundle takes bundleOverheadBytes on top of its size.
+	sizes := []int{92, 92, 292, 92, 192}
+
+	require.Equal(t, 0, trimBySize(sizes, 1000))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, keptBundles(sizes, 0))
+
+	// The largest bundles go first.
+	require.Equal(t, 2, trimBySize(sizes, 400))
+	require.Equal(t, []int{0, 1, 3}, keptBundles(sizes, 2))
+	// Among bundles of the same size, the last received goes first.
+	require.Equal(t, 3, trimBySize(sizes, 200))
+	require.Equal(t, []int{0, 1}, keptBundles(sizes, 3))
+
+	require.Equal(t, 5, trimBySize(sizes, 50))
+	require.Empty(t, keptBundles(sizes, 5))
+	require.Equal(t, 0, trimBySize(nil, 100))
+}
+
+// fakeBulkChain records the bundles of the bulk payloads broadcast, fulfilling the nonce of the
+// first one accepted like the chain does.
+type fakeBulkChain struct {
+	sent      [][]string
+	fulfilled bool
+	maxGas    int // max bundles of a tx, 0 for no limit
+}
+
+func (c *fakeBulkChain) broadcast(ctx context.Context, msgs ...sdktypes.Msg) (cosmosclient.Response, error) {
+	var workers []string
+	for _, bundle := range msgs[0].(*emissionstypes.MsgInsertBulkWorkerPayload).WorkerDataBundles {
+		workers = append(workers, bundle.Worker)
+	}
+	c.sent = append(c.sent, workers)
+	if c.maxGas > 0 && len(workers) > c.maxGas {
+		return cosmosclient.Response{}, fmt.Errorf("%w: tx needs too much gas", errGasAboveLimit)
+	}
+	if c.fulfilled {
+		return cosmosclient.Response{}, txCodeError(emissionstypes.ModuleName, emissionstypes.ErrNonceAlreadyFulfilled.ABCICode(), fmt.Errorf("tx failed with code 44"))
+	}
+	c.fulfilled = true
+	return cosmosclient.Response{TxResponse: &sdktypes.TxResponse{TxHash: fmt.Sprintf("TX%d", len(c.sent))}}, nil
+}
+
+func TestSendBulkPayload(t *testing.T) {
+	bundles := func(workers ...string) []*emissionstypes.WorkerDataBundle {
+		var bundles []*emissionstypes.WorkerDataBundle
+		for _, worker := range workers {
+			bundles = append(bundles, &emissionstypes.WorkerDataBundle{Worker: worker})
+		}
+		return bundles
+	}
+	nonce := &emissionstypes.Nonce{BlockHeight: 100}
+	dropped := func(topic string) float64 {
+		return testutil.ToFloat64(bulkBundles.WithLabelValues(topic, GasMsgTypeWorker, "dropped"))
+	}
+
+	// The payload goes in one tx, without its largest bundle when it does not fit.
+	chain := &fakeBulkChain{}
+	payload := workerBulkPayload("leader", 901, nonce, bundles("a", strings.Repeat("b", 200), "c"))
+	emptySize := payload.build(nil).(*emissionstypes.MsgInsertBulkWorkerPayload).Size()
+	ap := &AppChain{Config: AppChainConfig{MaxTxBytes: txOverheadBytes + emptySize + 100}, Logger: zerolog.Nop(), broadcast: chain.broadcast}
+	send := ap.sendBulkPayload(context.Background(), payload, 3, 0, 0)
+	require.NoError(t, send.err)
+	require.Equal(t, "TX1", send.txHash)
+	require.Equal(t, [][]string{{"a", "c"}}, chain.sent)
+	require.Equal(t, 1, send.dropped)
+	require.Equal(t, float64(1), dropped("901"))
+	require.Equal(t, float64(1), testutil.ToFloat64(workerChainCommit.WithLabelValues("901", "partial")))
+
+	// Once the nonce is fulfilled, the chain rejects any other payload for it, which is not retried.
+	send = ap.sendBulkPayload(context.Background(), workerBulkPayload("leader", 901, nonce, bundles("d")), 3, 0, 0)
+	require.ErrorIs(t, send.err, emissionstypes.ErrNonceAlreadyFulfilled)
+	require.Len(t, chain.sent, 2)
+	require.Equal(t, float64(1), testutil.ToFloat64(workerChainCommit.WithLabelValues("901", "failure")))
+
+	// Bundles are dropped until the tx needs no more gas than allowed.
+	chain = &fakeBulkChain{maxGas: 2}
+	ap.broadcast = chain.broadcast
+	ap.Config.MaxTxBytes = 0
+	send = ap.sendBulkPayload(context.Background(), workerBulkPayload("leader", 902, nonce, bundles("aaaa", "bbb", "cc", "d", "e")), 3, 0, 0)
+	require.NoError(t, send.err)
+	require.Equal(t, [][]string{{"aaaa", "bbb", "cc", "d", "e"}, {"cc", "d", "e"}, {"d", "e"}}, chain.sent)
+	require.Equal(t, float64(3), dropped("902"))
+
+	// Payloads without bundles are not sent.
+	send = ap.sendBulkPayload(context.Background(), workerBulkPayload("leader", 903, nonce, nil), 3, 0, 0)
+	require.NoError(t, send.err)
+	require.Len(t, chain.sent, 3)
+	require.Equal(t, float64(1), testutil.ToFloat64(workerChainCommit.WithLabelValues("903", "empty")))
+	require.Equal(t, float64(0), testutil.ToFloat64(workerChainCommit.WithLabelValues("903", "success")))
+}
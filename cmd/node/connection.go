@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ChainConnectionState describes the state of the node's connection to the Allora blockchain.
+type ChainConnectionState int32
+
+const (
+	ChainDisconnected ChainConnectionState = iota
+	ChainConnecting
+	ChainConnectedReadOnly
+	ChainConnectedSigning
+)
+
+var chainConnectionStates = []ChainConnectionState{
+	ChainDisconnected,
+	ChainConnecting,
+	ChainConnectedReadOnly,
+	ChainConnectedSigning,
+}
+
+func (s ChainConnectionState) String() string {
+	switch s {
+	case ChainDisconnected:
+		return "disconnected"
+	case ChainConnecting:
+		return "connecting"
+	case ChainConnectedReadOnly:
+		return "connected-readonly"
+	case ChainConnectedSigning:
+		return "connected-signing"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	chainMinBackoff   = time.Second
+	chainMaxBackoff   = time.Hour
+	chainProbeTimeout = 10 * time.Second
+)
+
+var errNoChainClient = errors.New("allora blockchain client is not available")
+
+// ChainConnection owns the node's AppChain client. It connects in the background, retries with
+// jittered exponential backoff, monitors the connection once established and swaps the client
+// atomically so that the executor and the result callback always see a consistent one.
+type ChainConnection struct {
+	cfg AppChainConfig
+	log zerolog.Logger
+
+	state    atomic.Int32
+	appChain atomic.Pointer[AppChain]
+
+	errLock sync.RWMutex
+	lastErr error
+
+	// connect and probe are replaceable for testing.
+	connect func(AppChainConfig, zerolog.Logger) (*AppChain, error)
+	probe   func(context.Context, *AppChain) error
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewChainConnection creates a connection manager. It does not connect until Run is called.
+func NewChainConnection(cfg AppChainConfig, log zerolog.Logger) *ChainConnection {
+	maxBackoff := time.Duration(cfg.ReconnectSeconds) * time.Second
+	if maxBackoff < chainMinBackoff {
+		maxBackoff = chainMinBackoff
+	}
+	if maxBackoff > chainMaxBackoff {
+		maxBackoff = chainMaxBackoff
+	}
+
+	c := &ChainConnection{
+		cfg:        cfg,
+		log:        log.With().Str("component", "chain-connection").Logger(),
+		connect:    connectToAlloraBlockchain,
+		probe:      probeAppChain,
+		minBackoff: chainMinBackoff,
+		maxBackoff: maxBackoff,
+	}
+	c.setState(ChainDisconnected)
+	return c
+}
+
+// AppChain returns the current chain client, or nil if there is none. It is safe to call on a nil receiver.
+func (c *ChainConnection) AppChain() *AppChain {
+	if c == nil {
+		return nil
+	}
+	return c.appChain.Load()
+}
+
+// State returns the current connection state. It is safe to call on a nil receiver.
+func (c *ChainConnection) State() ChainConnectionState {
+	if c == nil {
+		return ChainDisconnected
+	}
+	return ChainConnectionState(c.state.Load())
+}
+
+// LastError returns the error of the last failed connection attempt or probe, if any.
+func (c *ChainConnection) LastError() error {
+	if c == nil {
+		return nil
+	}
+	c.errLock.RLock()
+	defer c.errLock.RUnlock()
+	return c.lastErr
+}
+
+// Run connects to the chain and keeps the connection alive until the context is cancelled.
+// If reconnection is disabled, only a single connection attempt is made.
+func (c *ChainConnection) Run(ctx context.Context) {
+	attempt := 0
+	for {
+		state := c.establish()
+		if c.cfg.ReconnectSeconds == 0 {
+			return
+		}
+
+		// A signing connection, or a read-only one that has no account to sign with, is as good as
+		// it gets. Watch it and start over once it breaks.
+		if state == ChainConnectedSigning || (state == ChainConnectedReadOnly && c.cfg.AddressKeyName == "") {
+			attempt = 0
+			if !c.monitor(ctx) {
+				return
+			}
+			c.log.Warn().Err(c.LastError()).Msg("lost connection to allora blockchain")
+			c.swap(nil, ChainDisconnected)
+			continue
+		}
+
+		delay := backoffWithJitter(attempt, c.minBackoff, c.maxBackoff)
+		attempt++
+		c.log.Debug().Str("state", state.String()).Dur("retry_in", delay).Msg("attempting reconnection to allora blockchain")
+		if !sleepContext(ctx, delay) {
+			return
+		}
+	}
+}
+
+// establish makes a single connection attempt and publishes its outcome.
+func (c *ChainConnection) establish() ChainConnectionState {
+	c.setState(ChainConnecting)
+
+	appChain, err := c.connect(c.cfg, c.log)
+	c.setLastError(err)
+	switch {
+	case err != nil || appChain == nil:
+		c.swap(nil, ChainDisconnected)
+	case appChain.Config.SubmitTx:
+		c.swap(appChain, ChainConnectedSigning)
+	default:
+		c.swap(appChain, ChainConnectedReadOnly)
+	}
+
+	state := c.State()
+	c.log.Info().Str("state", state.String()).Msg("allora blockchain connection state")
+	return state
+}
+
+// monitor probes the current client periodically. It returns false if the context was cancelled,
+// and true once the connection is found to be broken.
+func (c *ChainConnection) monitor(ctx context.Context) bool {
+	for {
+		if !sleepContext(ctx, c.maxBackoff) {
+			return false
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, chainProbeTimeout)
+		err := c.probe(probeCtx, c.AppChain())
+		cancel()
+		if err != nil {
+			c.setLastError(err)
+			return true
+		}
+	}
+}
+
+func (c *ChainConnection) swap(appChain *AppChain, state ChainConnectionState) {
+	c.appChain.Store(appChain)
+	c.setState(state)
+}
+
+func (c *ChainConnection) setState(state ChainConnectionState) {
+	c.state.Store(int32(state))
+	for _, s := range chainConnectionStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		chainConnectionState.WithLabelValues(s.String()).Set(value)
+	}
+}
+
+func (c *ChainConnection) setLastError(err error) {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	c.lastErr = err
+}
+
+// ChainHealth is the health report of the chain connection.
+type ChainHealth struct {
+	Status    string `json:"status"`
+	Chain     string `json:"chain"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// chainHealthHandler reports the node health along with the state of its chain connection.
+// Nodes without a chain connection (e.g. head nodes) report it as disconnected.
+func chainHealthHandler(chain *ChainConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		health := ChainHealth{
+			Status: "ok",
+			Chain:  chain.State().String(),
+		}
+		if err := chain.LastError(); err != nil {
+			health.LastError = err.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(health)
+	}
+}
+
+// probeAppChain checks that at least one chain node behind the client still answers,
+// refreshing the wallet balance while at it.
+func probeAppChain(ctx context.Context, appChain *AppChain) error {
+	if appChain == nil || appChain.Endpoints == nil {
+		return errNoChainClient
+	}
+	err := appChain.Endpoints.Probe(ctx)
+	if err != nil {
+		return err
+	}
+	appChain.updateBalance(ctx)
+	return nil
+}
+
+// backoffWithJitter returns an exponentially growing delay capped at max, randomized
+// to between half and the full value so that many nodes do not retry in lockstep.
+func backoffWithJitter(attempt int, min, max time.Duration) time.Duration {
+	delay := max
+	if attempt < 32 && min<<attempt < max {
+		delay = min << attempt
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// sleepContext waits for the given duration. It returns false if the context was cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
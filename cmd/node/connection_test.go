@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChainConnection(reconnectSeconds uint64) *ChainConnection {
+	c := NewChainConnection(AppChainConfig{ReconnectSeconds: reconnectSeconds, AddressKeyName: "test"}, zerolog.Nop())
+	c.minBackoff = time.Millisecond
+	c.maxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestChainConnectionRetriesUntilSigning(t *testing.T) {
+	c := newTestChainConnection(1)
+
+	var attempts atomic.Int32
+	c.connect = func(cfg AppChainConfig, _ zerolog.Logger) (*AppChain, error) {
+		switch attempts.Add(1) {
+		case 1:
+			return nil, errors.New("connection refused")
+		case 2:
+			return &AppChain{Config: cfg}, nil
+		default:
+			cfg.SubmitTx = true
+			return &AppChain{Config: cfg}, nil
+		}
+	}
+	c.probe = func(context.Context, *AppChain) error { return nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	require.Eventually(t, func() bool { return c.State() == ChainConnectedSigning }, time.Second, time.Millisecond)
+	require.NotNil(t, c.AppChain())
+	require.True(t, c.AppChain().Config.SubmitTx)
+	require.EqualValues(t, 3, attempts.Load())
+}
+
+func TestChainConnectionReconnectsAfterFailedProbe(t *testing.T) {
+	c := newTestChainConnection(1)
+
+	var attempts atomic.Int32
+	c.connect = func(cfg AppChainConfig, _ zerolog.Logger) (*AppChain, error) {
+		attempts.Add(1)
+		cfg.SubmitTx = true
+		return &AppChain{Config: cfg}, nil
+	}
+	var probeErr atomic.Bool
+	probeErr.Store(true)
+	c.probe = func(context.Context, *AppChain) error {
+		if probeErr.CompareAndSwap(true, false) {
+			return errors.New("node unreachable")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	require.Eventually(t, func() bool { return attempts.Load() >= 2 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return c.State() == ChainConnectedSigning }, time.Second, time.Millisecond)
+}
+
+func TestChainConnectionWithoutReconnect(t *testing.T) {
+	c := newTestChainConnection(0)
+	c.connect = func(AppChainConfig, zerolog.Logger) (*AppChain, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	c.Run(context.Background())
+
+	require.Equal(t, ChainDisconnected, c.State())
+	require.Nil(t, c.AppChain())
+	require.Error(t, c.LastError())
+}
+
+func TestBackoffWithJitter(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		delay := backoffWithJitter(attempt, time.Second, time.Minute)
+		require.LessOrEqual(t, delay, time.Minute)
+		require.GreaterOrEqual(t, delay, time.Second/2)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+)
+
+const dryRunHTTPTimeout = 10 * time.Second
+
+// DryRunPayload is a leader transaction that was built and simulated instead of being broadcast.
+type DryRunPayload struct {
+	Time            time.Time       `json:"time"`
+	TopicId         uint64          `json:"topic_id"`
+	MsgType         string          `json:"msg_type"`
+	Sender          string          `json:"sender"`
+	GasEstimate     uint64          `json:"gas_estimate,omitempty"`
+	GasLimit        uint64          `json:"gas_limit,omitempty"`
+	Fees            string          `json:"fees,omitempty"`
+	SimulationError string          `json:"simulation_error,omitempty"`
+	Msg             json.RawMessage `json:"msg"`
+}
+
+// PayloadSink receives the payloads of a dry run.
+type PayloadSink interface {
+	Write(ctx context.Context, payload DryRunPayload) error
+}
+
+// NewPayloadSink creates a sink for the given output. URLs get the payloads POSTed as JSON, anything
+// else is taken as a file path that payloads are appended to as JSON lines. An empty output only logs.
+func NewPayloadSink(output string) PayloadSink {
+	switch {
+	case output == "":
+		return nil
+	case strings.HasPrefix(output, "http://") || strings.HasPrefix(output, "https://"):
+		return &httpPayloadSink{
+			url:    output,
+			client: &http.Client{Timeout: dryRunHTTPTimeout},
+		}
+	default:
+		return &filePayloadSink{path: output}
+	}
+}
+
+type filePayloadSink struct {
+	lock sync.Mutex
+	path string
+}
+
+func (s *filePayloadSink) Write(_ context.Context, payload DryRunPayload) error {
+	line, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+type httpPayloadSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpPayloadSink) Write(ctx context.Context, payload DryRunPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
+
+// dryRunPayload plans the gas and fees of the leader msg and hands it to the dry run sink instead of broadcasting it.
+func (ap *AppChain) dryRunPayload(ctx context.Context, topicId uint64, msg sdktypes.Msg) {
+	msgJSON, err := json.Marshal(msg)
+	if err != nil {
+		ap.Logger.Error().Err(err).Uint64("topic", topicId).Msg("dry run: could not marshal msg")
+		return
+	}
+
+	payload := DryRunPayload{
+		Time:    time.Now().UTC(),
+		TopicId: topicId,
+		MsgType: sdktypes.MsgTypeURL(msg),
+		Sender:  ap.Address,
+		Msg:     msgJSON,
+	}
+
+	plan, err := ap.PlanTx(ctx, msg)
+	if err != nil {
+		payload.SimulationError = err.Error()
+		ap.Logger.Warn().Err(err).Uint64("topic", topicId).Str("msg_type", payload.MsgType).Msg("dry run: tx simulation failed")
+	} else {
+		payload.GasEstimate = plan.SimulatedGas
+		payload.GasLimit = plan.Gas
+		payload.Fees = plan.Fees.String()
+	}
+
+	ap.Logger.Info().
+		Uint64("topic", topicId).
+		Str("msg_type", payload.MsgType).
+		Uint64("gas_estimate", payload.GasEstimate).
+		Uint64("gas_limit", payload.GasLimit).
+		Str("fees", payload.Fees).
+		Msg("dry run: built leader payload, not broadcasting")
+
+	if ap.Config.DryRunSink == nil {
+		return
+	}
+	err = ap.Config.DryRunSink.Write(ctx, payload)
+	if err != nil {
+		ap.Logger.Error().Err(err).Uint64("topic", topicId).Msg("dry run: could not write payload")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPayloadSink(t *testing.T) {
+	require.Nil(t, NewPayloadSink(""))
+	require.IsType(t, &httpPayloadSink{}, NewPayloadSink("https://example.com/payloads"))
+	require.IsType(t, &filePayloadSink{}, NewPayloadSink("payloads.jsonl"))
+}
+
+func TestFilePayloadSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payloads.jsonl")
+	sink := NewPayloadSink(path)
+
+	for topic := uint64(1); topic <= 2; topic++ {
+		err := sink.Write(context.Background(), DryRunPayload{TopicId: topic, Msg: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var payload DryRunPayload
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &payload))
+	require.EqualValues(t, 2, payload.TopicId)
+}
+
+func TestHTTPPayloadSink(t *testing.T) {
+	var received DryRunPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := NewPayloadSink(srv.URL).Write(context.Background(), DryRunPayload{TopicId: 7, Msg: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+	require.EqualValues(t, 7, received.TopicId)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	require.Error(t, NewPayloadSink(failing.URL).Write(context.Background(), DryRunPayload{Msg: json.RawMessage(`{}`)}))
+}
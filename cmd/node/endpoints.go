@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	endpointProbeTimeout = 10 * time.Second
+	// Weight of the newest sample in the endpoint latency moving average.
+	endpointLatencyWeight = 0.3
+)
+
+var errNoHealthyEndpoint = errors.New("no allora RPC endpoint available")
+
+// rpcEndpoint is a single CometBFT RPC endpoint of the Allora chain along with its observed health.
+type rpcEndpoint struct {
+	address string
+	client  *cosmosclient.Client // nil until the endpoint has been reached once
+	healthy bool
+	latency time.Duration // moving average of successful request latencies
+	lastErr error
+}
+
+// EndpointPool holds the chain RPC endpoints the node may use. Queries and broadcasts go to the
+// healthy endpoint with the lowest latency and fail over to the next one on transport errors.
+type EndpointPool struct {
+	lock      sync.RWMutex
+	endpoints []*rpcEndpoint
+
+	// Clients are created one at a time, cosmosclient updates global SDK config on creation.
+	createLock sync.Mutex
+	newClient  func(address string) (*cosmosclient.Client, error)
+
+	log zerolog.Logger
+}
+
+// NewEndpointPool creates a pool for the given addresses. Clients are created lazily by Probe.
+func NewEndpointPool(addresses []string, newClient func(address string) (*cosmosclient.Client, error), log zerolog.Logger) *EndpointPool {
+	pool := &EndpointPool{
+		newClient: newClient,
+		log:       log,
+	}
+	for _, address := range addresses {
+		pool.endpoints = append(pool.endpoints, &rpcEndpoint{address: address})
+	}
+	return pool
+}
+
+// Probe checks every endpoint concurrently, creating missing clients and refreshing health and latency.
+// It returns an error if no endpoint is healthy afterwards.
+func (p *EndpointPool) Probe(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, ep := range p.snapshot() {
+		wg.Add(1)
+		go func(ep *rpcEndpoint) {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := p.probeEndpoint(probeCtx, ep)
+			if err != nil {
+				p.reportFailure(ep, err)
+				return
+			}
+			p.reportSuccess(ep, time.Since(start))
+		}(ep)
+	}
+	wg.Wait()
+
+	if !p.Healthy() {
+		return errNoHealthyEndpoint
+	}
+	return nil
+}
+
+func (p *EndpointPool) probeEndpoint(ctx context.Context, ep *rpcEndpoint) error {
+	p.lock.RLock()
+	client := ep.client
+	p.lock.RUnlock()
+
+	if client != nil {
+		_, err := client.Status(ctx)
+		return err
+	}
+
+	p.createLock.Lock()
+	client, err := p.newClient(ep.address)
+	p.createLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	ep.client = client
+	p.lock.Unlock()
+	return nil
+}
+
+// Healthy reports whether at least one endpoint is usable.
+func (p *EndpointPool) Healthy() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	for _, ep := range p.endpoints {
+		if ep.healthy && ep.client != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Primary returns the client of the preferred endpoint, or nil if no endpoint was ever reached.
+func (p *EndpointPool) Primary() *cosmosclient.Client {
+	ordered := p.ordered()
+	if len(ordered) == 0 {
+		return nil
+	}
+	return ordered[0].client
+}
+
+// ordered returns the reachable endpoints, healthy ones first by ascending latency,
+// followed by the unhealthy ones in configuration order as a last resort.
+func (p *EndpointPool) ordered() []*rpcEndpoint {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	var healthy, unhealthy []*rpcEndpoint
+	for _, ep := range p.endpoints {
+		if ep.client == nil {
+			continue
+		}
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return healthy[i].latency < healthy[j].latency
+	})
+	return append(healthy, unhealthy...)
+}
+
+func (p *EndpointPool) snapshot() []*rpcEndpoint {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return append([]*rpcEndpoint(nil), p.endpoints...)
+}
+
+func (p *EndpointPool) reportSuccess(ep *rpcEndpoint, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = time.Duration(endpointLatencyWeight*float64(latency) + (1-endpointLatencyWeight)*float64(ep.latency))
+	}
+	if !ep.healthy {
+		p.log.Info().Str("endpoint", ep.address).Dur("latency", ep.latency).Msg("allora RPC endpoint is healthy")
+	}
+	ep.healthy = true
+	ep.lastErr = nil
+
+	rpcEndpointUp.WithLabelValues(ep.address).Set(1)
+	rpcEndpointLatency.WithLabelValues(ep.address).Set(ep.latency.Seconds())
+}
+
+func (p *EndpointPool) reportFailure(ep *rpcEndpoint, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if ep.healthy || ep.lastErr == nil {
+		p.log.Warn().Err(err).Str("endpoint", ep.address).Msg("allora RPC endpoint is unhealthy")
+	}
+	ep.healthy = false
+	ep.lastErr = err
+
+	rpcEndpointUp.WithLabelValues(ep.address).Set(0)
+}
+
+// isTransportError tells failures of the endpoint itself apart from errors returned by the chain,
+// which would be the same on any endpoint.
+func isTransportError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+	return st.Code() == codes.Unavailable
+}
+
+// failoverConn is a gRPC client connection that sends queries through the endpoint pool.
+type failoverConn struct {
+	pool *EndpointPool
+}
+
+// Invoke runs the query on the preferred endpoint, moving on to the next one on transport errors.
+func (f failoverConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	err := errNoHealthyEndpoint
+	for _, ep := range f.pool.ordered() {
+		start := time.Now()
+		err = ep.client.Context().Invoke(ctx, method, args, reply, opts...)
+		if err == nil || !isTransportError(err) {
+			f.pool.reportSuccess(ep, time.Since(start))
+			return err
+		}
+
+		f.pool.reportFailure(ep, err)
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// NewStream is not supported, the same as for the cosmos-sdk client context.
+func (f failoverConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("streaming rpc not supported")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEndpointPoolOrdering(t *testing.T) {
+	pool := NewEndpointPool([]string{"http://a", "http://b", "http://c", "http://d"}, nil, zerolog.Nop())
+	a, b, c, d := pool.endpoints[0], pool.endpoints[1], pool.endpoints[2], pool.endpoints[3]
+	for _, ep := range []*rpcEndpoint{a, b, c} {
+		ep.client = &cosmosclient.Client{}
+	}
+
+	pool.reportSuccess(a, 300*time.Millisecond)
+	pool.reportSuccess(b, 100*time.Millisecond)
+	pool.reportSuccess(c, 10*time.Millisecond)
+	pool.reportFailure(c, errors.New("connection refused"))
+
+	// Healthy endpoints by latency, then unhealthy ones; unreachable endpoints are left out.
+	require.Equal(t, []*rpcEndpoint{b, a, c}, pool.ordered())
+	require.Same(t, b.client, pool.Primary())
+	require.True(t, pool.Healthy())
+	require.NotContains(t, pool.ordered(), d)
+
+	// A recovered endpoint competes on its latency again.
+	pool.reportSuccess(c, 10*time.Millisecond)
+	require.Equal(t, []*rpcEndpoint{c, b, a}, pool.ordered())
+}
+
+func TestEndpointPoolLatencyAverage(t *testing.T) {
+	pool := NewEndpointPool([]string{"http://a"}, nil, zerolog.Nop())
+	ep := pool.endpoints[0]
+
+	pool.reportSuccess(ep, 100*time.Millisecond)
+	require.Equal(t, 100*time.Millisecond, ep.latency)
+
+	pool.reportSuccess(ep, 200*time.Millisecond)
+	require.Equal(t, 130*time.Millisecond, ep.latency)
+}
+
+func TestIsTransportError(t *testing.T) {
+	require.True(t, isTransportError(errors.New("post failed: connection refused")))
+	require.True(t, isTransportError(status.Error(codes.Unavailable, "unavailable")))
+	require.False(t, isTransportError(status.Error(codes.NotFound, "worker not found")))
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
-	"strings"
+	"time"
 
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
 	"github.com/allora-network/b7s/api"
 	"github.com/allora-network/b7s/models/blockless"
 	"github.com/allora-network/b7s/models/codes"
@@ -16,35 +18,34 @@ import (
 	"github.com/allora-network/b7s/node/aggregate"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const B7S_TOPIC_FORMAT_PREFIX = "allora-topic-"
-
-// ExecuteRequest describes the payload for the REST API request for function execution.
-type ExecuteRequest struct {
-	execute.Request
-	Topic string `json:"topic,omitempty"`
-}
-
-// ExecuteResponse describes the REST API response for function execution.
-type ExecuteResponse struct {
-	Code      codes.Code        `json:"code,omitempty"`
-	RequestID string            `json:"request_id,omitempty"`
-	Message   string            `json:"message,omitempty"`
-	Results   aggregate.Results `json:"results,omitempty"`
-	Cluster   execute.Cluster   `json:"cluster,omitempty"`
-}
+// Types of the head REST API, shared with its clients.
+type (
+	ExecuteRequest  = headapi.ExecuteRequest
+	ExecuteResponse = headapi.ExecuteResponse
+)
 
 func sendResultsToChain(log zerolog.Logger, appChainClient *AppChain, res node.ChanData) {
 	log.Info().Msg("Sending Results to chain")
-	if appChainClient == nil || res.Res != codes.OK {
+	// Continue the trace of the request this leader executed itself.
+	reqCtx, span := tracer.Start(executedRequests.context(context.Background(), res.RequestId), "leader.submit",
+		trace.WithAttributes(attrRequestID.String(res.RequestId), attribute.String("allora.b7s_topic", res.Topic)))
+	defer span.End()
+	if appChainClient == nil || !appChainClient.Config.SubmitTx || res.Res != codes.OK {
 		reason := "unknown"
 		if appChainClient == nil {
 			reason = "AppChainClient is disabled"
+		} else if !appChainClient.Config.SubmitTx {
+			reason = "AppChainClient cannot sign transactions"
 		} else if res.Res != codes.OK {
 			reason = fmt.Sprintf("Response code is not OK: %s", res.Res)
 		}
 		log.Warn().Msgf("Worker results not submitted to chain, not attempted. Reason: %s", reason)
+		span.SetAttributes(attribute.String("allora.skipped", reason))
 		return
 	}
 	stdout := aggregate.Aggregate(res.Data)[0].Result.Stdout
@@ -52,33 +53,22 @@ func sendResultsToChain(log zerolog.Logger, appChainClient *AppChain, res node.C
 
 	log.Debug().Str("Topic", res.Topic).Str("worker mode", appChainClient.Config.WorkerMode).Msg("Found topic ID")
 
-	reqCtx := context.Background()
-	numTopicId := "0"
-	// remove prffix and suffix from "allora-topic-{xxx}-reputer/worker"
-	if strings.Contains(res.Topic, "reputer") {
-		numTopicId = res.Topic[len(B7S_TOPIC_FORMAT_PREFIX) : len(res.Topic)-len(WorkerModeReputer)-1]
-	} else {
-		numTopicId = res.Topic[len(B7S_TOPIC_FORMAT_PREFIX) : len(res.Topic)-len(WorkerModeWorker)-1]
-	}
-	topicId, err := strconv.ParseUint(numTopicId, 10, 64)
+	topic, err := ParseTopicRef(res.Topic)
 	if err != nil {
-		log.Error().Str("Topic", res.Topic).Str("worker mode", appChainClient.Config.WorkerMode).Err(err).Msg("Cannot parse reputer topic ID")
+		log.Error().Str("Topic", res.Topic).Str("worker mode", appChainClient.Config.WorkerMode).Err(err).Msg("Cannot parse topic")
+		span.SetStatus(otelcodes.Error, err.Error())
 		return
 	}
+	topicId := topic.ID
+	span.SetAttributes(attrTopic.Int64(int64(topicId)), attrMode.String(appChainClient.Config.WorkerMode))
 	if appChainClient.Config.WorkerMode == WorkerModeWorker { // for inference or forecast
 		appChainClient.SendWorkerModeData(reqCtx, topicId, aggregate.Aggregate(res.Data))
-
-		// increament the number of commits made by worker
-		workerChainCommit.Inc()
 	} else { // for losses
 		appChainClient.SendReputerModeData(reqCtx, topicId, aggregate.Aggregate(res.Data))
-
-		// increament the number of commits made by reputer
-		reputerChainCommit.Inc()
 	}
 }
 
-func createExecutor(a api.API) func(ctx echo.Context) error {
+func createExecutor(a api.API, history *HistoryStore, admission *Admission) func(ctx echo.Context) error {
 
 	return func(ctx echo.Context) error {
 
@@ -86,51 +76,213 @@ func createExecutor(a api.API) func(ctx echo.Context) error {
 		var req ExecuteRequest
 		err := ctx.Bind(&req)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("could not unpack request: %w", err))
+			return badRequest(fmt.Sprintf("could not unpack request: %s", err))
 		}
 
-		a.Log.Debug().Msgf("Request: %+v", req)
-		// Add the topic to the req.Config.Environment vars as TOPIC_ID
-		// This is used by the Allora Extension to know which topic it is being executed on
-		req.Config.Environment = append(req.Config.Environment, execute.EnvVar{
-			Name:  "TOPIC_ID",
-			Value: req.Topic,
-		})
-
-		// Get the execution result.
-		code, id, results, cluster, err := a.Node.ExecuteFunction(ctx.Request().Context(), execute.Request(req.Request), buildb7sTopic(req.Topic))
+		release, err := admission.Acquire(ctx.Request().Context())
 		if err != nil {
-			a.Log.Warn().Str("function", req.FunctionID).Err(err).Msg("node failed to execute function")
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
 		}
+		defer release()
+
+		res, _ := executeRequest(ctx.Request().Context(), a, history, req, nil)
 
-		// Transform the node response format to the one returned by the API.
-		res := ExecuteResponse{
-			Code:      code,
-			RequestID: id,
-			Results:   aggregate.Aggregate(results),
-			Cluster:   cluster,
+		// Send the response, with the status of its error if it failed.
+		status := http.StatusOK
+		if res.Error != nil {
+			status = executionErrorStatus(res.Error.Code)
 		}
-		a.Log.Debug().Msgf("Response: %+v", res)
-		// Communicate the reason for failure in these cases.
-		if errors.Is(err, blockless.ErrRollCallTimeout) || errors.Is(err, blockless.ErrExecutionNotEnoughNodes) {
-			res.Message = err.Error()
+		return ctx.JSON(status, res)
+	}
+}
+
+// executeRequest has the request executed by the workers of its topic and records it in the history.
+// onSubmission, if set, gets the outcome of the chain submission of the results once it is known.
+// The returned flag tells whether the chain submission is looked up at all.
+func executeRequest(ctx context.Context, a api.API, history *HistoryStore, req ExecuteRequest, onSubmission func(ChainSubmission)) (ExecuteResponse, bool) {
+	a.Log.Debug().
+		Str("function", req.FunctionID).
+		Str("method", req.Method).
+		Str("topic", req.Topic).
+		Interface("environment", redactEnvironment(req.Config.Environment)).
+		Msg("execution request")
+	// Add the topic to the req.Config.Environment vars as TOPIC_ID
+	// This is used by the Allora Extension to know which topic it is being executed on
+	req.Config.Environment = append(req.Config.Environment, execute.EnvVar{
+		Name:  "TOPIC_ID",
+		Value: req.Topic,
+	})
+
+	topic, err := ParseRequestTopic(req.Topic)
+	if err != nil {
+		a.Log.Warn().Str("topic", req.Topic).Err(err).Msg("invalid execution topic")
+		message := err.Error()
+		return ExecuteResponse{
+			Code:    codes.Invalid,
+			Message: message,
+			Error:   &headapi.ExecutionError{Code: headapi.ErrorBadRequest, Message: message},
+		}, false
+	}
+	topicLabel, mode := topicMetricLabels(topic)
+	reqCtx, span := tracer.Start(ctx, "head.execute",
+		trace.WithAttributes(attrTopic.String(topic.IDString()), attrMode.String(topic.Mode), attribute.String("allora.function", req.FunctionID)))
+	defer span.End()
+
+	// Get the execution result. The roll call and the execution on the workers happen within b7s,
+	// the workers continue the trace from the context passed in the request environment.
+	start := time.Now()
+	execCtx, execSpan := tracer.Start(reqCtx, "b7s.execute")
+	injectTraceContext(execCtx, &req.Config.Environment)
+	code, id, results, cluster, err := a.Node.ExecuteFunction(execCtx, execute.Request(req.Request), topic.Format())
+	execSpan.SetAttributes(attrRequestID.String(id), attribute.String("allora.code", code.String()), attribute.Int("allora.peers", len(cluster.Peers)))
+	endSpan(execSpan, err)
+	span.SetAttributes(attrRequestID.String(id))
+	if err != nil {
+		a.Log.Warn().Str("function", req.FunctionID).Err(err).Msg("node failed to execute function")
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+
+	// Transform the node response format to the one returned by the API.
+	res := ExecuteResponse{
+		Code:      code,
+		RequestID: id,
+		Results:   aggregate.Aggregate(results),
+		Cluster:   cluster,
+	}
+	a.Log.Debug().
+		Str("request", res.RequestID).
+		Str("code", res.Code.String()).
+		Int("results", len(res.Results)).
+		Msg("execution response")
+	// Communicate the reason for failure.
+	res.Error = executionError(req, code, results, err)
+	if res.Error != nil {
+		res.Message = res.Error.Message
+	}
+
+	tracked := history.Record(ExecutionRecord{
+		RequestID:   res.RequestID,
+		Topic:       req.Topic,
+		FunctionID:  req.FunctionID,
+		Method:      req.Method,
+		Environment: redactEnvironment(req.Config.Environment),
+		Nonce:       requestNonce(req.Config.Environment),
+		StartedAt:   start,
+		FinishedAt:  time.Now(),
+		Code:        res.Code,
+		Message:     res.Message,
+		ErrorCode:   errorCode(res.Error),
+		Cluster:     res.Cluster,
+		Outputs:     peerOutputs(results),
+		Results:     res.Results,
+	}, onSubmission)
+
+	// increament the number of requests made by the head
+	headRequests.WithLabelValues(topicLabel, mode, code.String()).Inc()
+	executionDuration.WithLabelValues(blockless.HeadNode.String(), topicLabel, mode, code.String()).Observe(time.Since(start).Seconds())
+
+	return res, tracked
+}
+
+// executionError classifies a failed execution from the b7s code, the results of the workers and
+// the error of the node, if any. It returns nil if the execution succeeded.
+func executionError(req ExecuteRequest, code codes.Code, results execute.ResultMap, err error) *headapi.ExecutionError {
+	failures := peerFailures(results)
+	execErr := &headapi.ExecutionError{Code: headapi.ErrorExecutionFailed, Peers: failures}
+	switch {
+	case code == codes.Timeout || errors.Is(err, blockless.ErrRollCallTimeout):
+		// Without a node count, the roll call only times out if no worker answered.
+		if req.Config.NodeCount < 1 {
+			execErr.Code = headapi.ErrorNoWorkers
+			execErr.Message = fmt.Sprintf("no worker of topic %s answered the roll call", req.Topic)
+		} else {
+			execErr.Code = headapi.ErrorRollCallTimeout
+			execErr.Message = fmt.Sprintf("fewer than %d workers of topic %s answered the roll call", req.Config.NodeCount, req.Topic)
 		}
+	case code == codes.OK && len(results) > 0 && len(failures) == len(results):
+		if allFailedWith(failures, codes.NotFound) {
+			execErr.Code = headapi.ErrorUnknownFunction
+			execErr.Message = fmt.Sprintf("function %s is not installed on the workers", req.FunctionID)
+		} else if allFailedWith(failures, codes.Invalid) {
+			execErr.Code = headapi.ErrorInvalidOutput
+			execErr.Message = fmt.Sprintf("all workers returned invalid output: %s", failures[0].Stderr)
+		} else {
+			execErr.Message = "all workers failed to execute the function"
+		}
+	case code == codes.OK:
+		return nil
+	case code == codes.NoContent:
+		execErr.Message = "no worker returned a result"
+	case code == codes.PartialContent || errors.Is(err, blockless.ErrExecutionNotEnoughNodes):
+		execErr.Message = "not enough workers returned a result"
+	case err != nil:
+		execErr.Message = err.Error()
+	default:
+		execErr.Message = fmt.Sprintf("execution failed with code %s", code)
+	}
+	return execErr
+}
 
-		// increament the number of responses made by reputer
-		headRequests.Inc()
+// peerFailures returns the results of the workers that failed to execute the function, by peer ID.
+func peerFailures(results execute.ResultMap) []headapi.PeerFailure {
+	var failures []headapi.PeerFailure
+	for peer, res := range results {
+		if res.Code == codes.OK && res.Result.ExitCode == 0 {
+			continue
+		}
+		failures = append(failures, headapi.PeerFailure{
+			Peer:     peer.String(),
+			Code:     res.Code,
+			ExitCode: res.Result.ExitCode,
+			Stderr:   res.Result.Stderr,
+		})
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Peer < failures[j].Peer })
+	return failures
+}
+
+func allFailedWith(failures []headapi.PeerFailure, code codes.Code) bool {
+	for _, f := range failures {
+		if f.Code != code {
+			return false
+		}
+	}
+	return true
+}
 
-		// Send the response.
-		return ctx.JSON(http.StatusOK, res)
+func errorCode(err *headapi.ExecutionError) string {
+	if err == nil {
+		return ""
 	}
+	return err.Code
 }
 
-func buildb7sTopic(alloraTopic string) string {
-	res := ""
-	if strings.Contains(alloraTopic, "reputer") {
-		topicNum := alloraTopic[0 : len(alloraTopic)-8]
-		res = B7S_TOPIC_FORMAT_PREFIX + topicNum + "-" + WorkerModeReputer
-	} else {
-		res = B7S_TOPIC_FORMAT_PREFIX + alloraTopic + "-" + WorkerModeWorker
+// executionErrorStatus returns the HTTP status of the responses failing with the error code.
+func executionErrorStatus(code string) int {
+	switch code {
+	case headapi.ErrorBadRequest:
+		return http.StatusBadRequest
+	case headapi.ErrorUnknownFunction:
+		return http.StatusNotFound
+	case headapi.ErrorNoWorkers:
+		return http.StatusServiceUnavailable
+	case headapi.ErrorRollCallTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// requestNonce returns the block height of the topic nonce a request is made for, or 0 if it has none.
+func requestNonce(env []execute.EnvVar) int64 {
+	for _, v := range env {
+		if v.Name == "ALLORA_BLOCK_HEIGHT_CURRENT" {
+			nonce, err := strconv.ParseInt(v.Value, 10, 64)
+			if err != nil {
+				return 0
+			}
+			return nonce
+		}
 	}
-	return res
+	return 0
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/allora-network/b7s/models/blockless"
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionError(t *testing.T) {
+	req := ExecuteRequest{Request: execute.Request{FunctionID: "bafy"}, Topic: "1"}
+	ok := execute.Result{Code: codes.OK, Result: execute.RuntimeOutput{Stdout: "1"}}
+	notFound := execute.Result{Code: codes.NotFound}
+	crashed := execute.Result{Code: codes.OK, Result: execute.RuntimeOutput{ExitCode: 1, Stderr: "panic"}}
+
+	require.Nil(t, executionError(req, codes.OK, execute.ResultMap{"a": ok}, nil))
+	// Some failures do not fail the execution, but all of them do.
+	require.Nil(t, executionError(req, codes.OK, execute.ResultMap{"a": ok, "b": crashed}, nil))
+
+	err := executionError(req, codes.OK, execute.ResultMap{"a": notFound, "b": notFound}, nil)
+	require.Equal(t, headapi.ErrorUnknownFunction, err.Code)
+	require.Len(t, err.Peers, 2)
+
+	invalid := execute.Result{Code: codes.Invalid, Result: execute.RuntimeOutput{Stderr: "invalid worker output: output is empty"}}
+	err = executionError(req, codes.OK, execute.ResultMap{"a": invalid}, nil)
+	require.Equal(t, headapi.ErrorInvalidOutput, err.Code)
+	require.Equal(t, "all workers returned invalid output: invalid worker output: output is empty", err.Message)
+	require.Equal(t, headapi.ErrorExecutionFailed, executionError(req, codes.OK, execute.ResultMap{"a": invalid, "b": notFound}, nil).Code)
+
+	err = executionError(req, codes.OK, execute.ResultMap{"a": notFound, "b": crashed}, nil)
+	require.Equal(t, headapi.ErrorExecutionFailed, err.Code)
+	require.Equal(t, []headapi.PeerFailure{
+		{Peer: peer.ID("a").String(), Code: codes.NotFound},
+		{Peer: peer.ID("b").String(), Code: codes.OK, ExitCode: 1, Stderr: "panic"},
+	}, err.Peers)
+
+	err = executionError(req, codes.Timeout, nil, fmt.Errorf("roll call: %w", blockless.ErrRollCallTimeout))
+	require.Equal(t, headapi.ErrorNoWorkers, err.Code)
+	req.Config.NodeCount = 3
+	err = executionError(req, codes.Timeout, nil, nil)
+	require.Equal(t, headapi.ErrorRollCallTimeout, err.Code)
+	require.Equal(t, "fewer than 3 workers of topic 1 answered the roll call", err.Message)
+
+	require.Equal(t, headapi.ErrorExecutionFailed, executionError(req, codes.NoContent, nil, nil).Code)
+	require.Equal(t, headapi.ErrorExecutionFailed, executionError(req, codes.PartialContent, execute.ResultMap{"a": ok}, nil).Code)
+	require.Equal(t, "execution failed with code 500", executionError(req, codes.Error, nil, nil).Message)
+}
+
+func TestExecutionErrorStatus(t *testing.T) {
+	require.Equal(t, http.StatusNotFound, executionErrorStatus(headapi.ErrorUnknownFunction))
+	require.Equal(t, http.StatusServiceUnavailable, executionErrorStatus(headapi.ErrorNoWorkers))
+	require.Equal(t, http.StatusGatewayTimeout, executionErrorStatus(headapi.ErrorRollCallTimeout))
+	require.Equal(t, http.StatusBadGateway, executionErrorStatus(headapi.ErrorExecutionFailed))
+}
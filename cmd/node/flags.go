@@ -22,6 +22,8 @@ func parseFlags() *alloraCfg {
 	var cfg alloraCfg
 
 	pflag.StringVarP(&cfg.Log.Level, "log-level", "l", "info", "log level to use")
+	pflag.StringVar(&cfg.LogFormat, "log-format", LogFormatConsole, "log format to use (console or json)")
+	pflag.StringToStringVar(&cfg.LogLevels, "log-levels", nil, "log level per subsystem (executor, appchain, api or p2p), e.g. executor=debug,p2p=warn")
 
 	// Node configuration.
 	pflag.StringVarP(&cfg.Role, "role", "r", defaultRole, "role this note will have in the Blockless protocol (head or worker)")
@@ -29,6 +31,7 @@ func parseFlags() *alloraCfg {
 	pflag.StringVar(&cfg.FunctionDatabasePath, "function-db", defaultFunctionDB, "path to the database used for persisting function data")
 	pflag.UintVarP(&cfg.Concurrency, "concurrency", "c", defaultConcurrency, "maximum number of requests node will process in parallel")
 	pflag.StringVar(&cfg.API, "rest-api", "", "address where the head node REST API will listen on")
+	pflag.StringVar(&cfg.GRPCAPI, "grpc-api", "", "address where the head node gRPC API will listen on, disabled if empty")
 	pflag.StringVar(&cfg.Workspace, "workspace", "./workspace", "directory that the node can use for file storage")
 	pflag.StringVar(&cfg.RuntimePath, "runtime-path", "", "runtime path (used by the worker node)")
 	pflag.StringVar(&cfg.RuntimeCLI, "runtime-cli", "", "runtime path (used by the worker node)")
@@ -57,17 +60,76 @@ func parseFlags() *alloraCfg {
 	// Allora L1 configuration
 	pflag.StringVarP(&cfg.AppChainConfig.AlloraHomeDir, "allora-chain-home-dir", "", "", "The Home folder of the client, use the user home if not set")
 	pflag.StringVarP(&cfg.AppChainConfig.AddressKeyName, "allora-chain-key-name", "", "", "The name of a key stored in the Allora Blockchain Wallet")
-	pflag.StringVarP(&cfg.AppChainConfig.AddressRestoreMnemonic, "allora-chain-restore-mnemonic", "", "", "The restore mnemonic for an Allora Blockchain Wallet")
-	pflag.StringVarP(&cfg.AppChainConfig.AddressAccountPassphrase, "allora-chain-account-password", "", "", "The password for an Allora Blockchain Wallet Key")
-	pflag.StringVarP(&cfg.AppChainConfig.NodeRPCAddress, "allora-node-rpc-address", "", "http://localhost:26657", "The address for the client to connect to a node.")
+	pflag.StringVarP(&cfg.RestoreMnemonic, "allora-chain-restore-mnemonic", "", "", "The restore mnemonic for an Allora Blockchain Wallet")
+	pflag.StringVar(&cfg.RestoreMnemonicFile, "allora-chain-restore-mnemonic-file", "", "File containing the restore mnemonic for an Allora Blockchain Wallet. Read from "+envRestoreMnemonic+" if neither this nor the mnemonic is set.")
+	pflag.StringVarP(&cfg.AccountPassphrase, "allora-chain-account-password", "", "", "The password for an Allora Blockchain Wallet Key")
+	pflag.StringVar(&cfg.AccountPassphraseFile, "allora-chain-account-password-file", "", "File containing the password for an Allora Blockchain Wallet Key. Read from "+envAccountPassphrase+" if neither this nor the password is set.")
+	pflag.StringSliceVar(&cfg.AppChainConfig.NodeRPCAddresses, "allora-node-rpc-address", []string{"http://localhost:26657"}, "The addresses of the nodes for the client to connect to. Requests go to the fastest healthy one and fail over to the others.")
 	pflag.StringSliceVar(&cfg.AppChainConfig.TopicIds, "allora-chain-topic-id", nil, "The topic id for the topic that the node will subscribe to.")
-	pflag.Uint64Var(&cfg.AppChainConfig.ReconnectSeconds, "allora-chain-reconnect-seconds", 60, "If connection to Allora Appchain breaks, it will attempt to reconnect with this interval. O means no reconnection.")
+	pflag.Uint64Var(&cfg.AppChainConfig.ReconnectSeconds, "allora-chain-reconnect-seconds", 60, "Max interval between reconnection attempts (with backoff) and connection checks for the Allora Appchain. 0 means no reconnection.")
 	pflag.Int64Var(&cfg.AppChainConfig.InitialStake, "allora-chain-initial-stake", 0, "Upon registering on a new topic, amount of stake to use.")
 	pflag.StringVarP(&cfg.AppChainConfig.WorkerMode, "allora-chain-worker-mode", "", WorkerModeWorker, "Worker mode of an Allora Network node.")
 	pflag.StringVar(&cfg.AppChainConfig.Gas, "allora-chain-gas", "auto", "Max gas on Allora client.")
 	pflag.Float64Var(&cfg.AppChainConfig.GasAdjustment, "allora-chain-gas-adjustment", 0.1, "Gas adjustment on Allora client.")
+	pflag.StringSliceVar(&cfg.AppChainConfig.GasStrategySpecs, "allora-chain-gas-strategy", nil, "Gas strategy per msg type (worker, reputer, register, stake or default) as <msg type>=simulate[:<multiplier>], <msg type>=fixed:<gas> or <msg type>=capped:<multiplier>:<max gas>.")
+	pflag.StringSliceVar(&cfg.AppChainConfig.ValueTransformSpecs, "allora-chain-value-transform", nil, "Transform of the loss values of a topic (topic ID or default) before submission, as <topic id>=<step>[|<step>...] with steps identity, log10, ln, clamp:<min>:<max> or scale:<factor>. Defaults to log10, or identity for topics allowing negative values.")
+	pflag.StringSliceVar(&cfg.AppChainConfig.ValueErrorPolicySpecs, "allora-chain-value-error-policy", nil, "What to do with values failing to transform, per topic (topic ID or default), as <topic id>=fail to fail the bundle or <topic id>=drop to leave them out.")
+	pflag.DurationVar(&cfg.AppChainConfig.TopicCacheTTL, "allora-chain-topic-cache-ttl", defaultTopicCacheTTL, "How long topic definitions fetched from the chain are cached. 0 fetches them for every execution.")
+	pflag.StringVar(&cfg.AppChainConfig.TopicEnvMismatch, "allora-chain-topic-env-mismatch", TopicEnvMismatchWarn, "What to do with executions whose environment (LOSS_FUNCTION_ALLOWS_NEGATIVE, LOSS_METHOD, EPOCH_LENGTH, GROUND_TRUTH_LAG) disagrees with the topic on chain: warn and use the chain values, or reject them. reject also fails executions whose topic cannot be fetched from chain.")
+	pflag.StringVar(&cfg.AppChainConfig.GasPricesSpec, "allora-chain-gas-prices", "", "Gas prices paid for leader transactions, e.g. 0.025uallo. No fees are paid if not set.")
+	pflag.StringVar(&cfg.AppChainConfig.FeeBudgetLimit, "allora-chain-fee-budget", "", "Max fees the account may spend per budget window, e.g. 5000000uallo. Transactions are refused once it is spent. Needs --allora-chain-gas-prices. Unlimited if not set.")
+	pflag.StringVar(&cfg.AppChainConfig.FeeBudgetWindow, "allora-chain-fee-budget-window", defaultFeeBudgetWindow, "Window the fee budget applies to, a duration (24h) or a number of blocks (720blocks).")
+	pflag.IntVar(&cfg.AppChainConfig.MaxTxBytes, "allora-chain-max-tx-bytes", defaultMaxTxBytes, "Max size of a leader transaction. The largest bundles of larger bulk payloads are dropped.")
+	pflag.Uint64Var(&cfg.AppChainConfig.MaxTxGas, "allora-chain-max-tx-gas", 0, "Max gas of a leader transaction. Bulk payloads needing more have the largest half of their bundles dropped until they fit. 0 means no limit.")
+	pflag.BoolVar(&cfg.AppChainConfig.DryRun, "dry-run", false, "Sign bundles and build and simulate leader transactions, but do not broadcast them or register with the Allora chain.")
+	pflag.StringVar(&cfg.AppChainConfig.DryRunOutput, "dry-run-output", "", "File (JSON lines) or http(s) URL (POST) receiving the dry run transactions. Only logged if not set.")
+
+	// Metrics server configuration.
+	pflag.StringVar(&cfg.MetricsConfig.Address, "metrics-address", defaultMetricsAddress, "Address of the server exposing metrics and health. Empty to disable it.")
+	pflag.StringVar(&cfg.MetricsConfig.BasicAuthUser, "metrics-basic-auth-user", "", "User required to access the metrics server. The password is needed too.")
+	pflag.StringVar(&cfg.MetricsConfig.BasicAuthPasswordFile, "metrics-basic-auth-password-file", "", "File containing the password required to access the metrics server. Read from "+envMetricsPassword+" if not set.")
+	pflag.StringVar(&cfg.MetricsConfig.BearerTokenFile, "metrics-bearer-token-file", "", "File containing a bearer token accepted by the metrics server. Read from "+envMetricsBearerToken+" if not set.")
+	pflag.StringVar(&cfg.MetricsConfig.TLSCertFile, "metrics-tls-cert", "", "TLS certificate file of the metrics server. Served over plain HTTP if not set.")
+	pflag.StringVar(&cfg.MetricsConfig.TLSKeyFile, "metrics-tls-key", "", "TLS key file of the metrics server.")
+	pflag.StringSliceVar(&cfg.MetricsConfig.Topics, "metrics-topic", nil, "Topic IDs the head labels its execution metrics with, besides those of --allora-chain-topic-id and of the API client allowlists. Other topics are labelled other.")
+	pflag.BoolVar(&cfg.MetricsConfig.Pprof, "metrics-pprof", false, "Expose pprof endpoints under /debug/pprof/ on the metrics server.")
+
+	// Execution history configuration.
+	pflag.StringVar(&cfg.HistoryConfig.DatabasePath, "history-db", "", "path to the database used by the head for persisting the execution history, "+defaultHistoryDB+" in the workspace if not set")
+	pflag.IntVar(&cfg.HistoryConfig.Size, "history-size", defaultHistorySize, "Number of executions kept in the head execution history, the oldest are dropped first. 0 disables the history.")
+	pflag.BoolVar(&cfg.HistoryConfig.TrackSubmissions, "history-track-submissions", false, "Look up the chain transactions in which the results of recorded executions were submitted, using --allora-node-rpc-address.")
+	pflag.DurationVar(&cfg.HistoryConfig.SubmissionTimeout, "history-submission-timeout", defaultSubmissionTimeout, "How long to look for the chain submission of an execution.")
+
+	// Head API configuration.
+	pflag.DurationVar(&cfg.AsyncJobRetention, "async-job-retention", defaultAsyncJobRetention, "How long the state of a finished asynchronous execution can be polled.")
+	pflag.StringSliceVar(&cfg.AsyncCallbackNets, "async-callback-allowed-network", nil, "Network, in CIDR notation, asynchronous execution callbacks can be posted to on top of public addresses, e.g. 10.0.0.0/8. Can be repeated.")
+	pflag.IntVar(&cfg.BatchConcurrency, "batch-concurrency", defaultBatchConcurrency, "Number of requests of a batch executed at the same time.")
+	pflag.IntVar(&cfg.BatchMaxSize, "batch-max-size", defaultBatchMaxSize, "Max number of requests in a batch.")
+	pflag.Int64Var(&cfg.MaxRequestBody, "rest-api-max-body-size", defaultMaxRequestBodySize, "Max size of REST API request bodies, in bytes. Larger requests are rejected with 413.")
+	pflag.StringVar(&cfg.APIAuth.ClientsFile, "rest-api-auth-config", "", "JSON file of the REST API clients, with their credentials, permissions and topics. Without it the REST API is open.")
+	pflag.StringVar(&cfg.APIAuth.TLSCertFile, "rest-api-tls-cert", "", "TLS certificate of the REST API, serving it over HTTPS.")
+	pflag.StringVar(&cfg.APIAuth.TLSKeyFile, "rest-api-tls-key", "", "TLS key of the REST API.")
+	pflag.StringVar(&cfg.APIAuth.ClientCAFile, "rest-api-client-ca", "", "CA verifying the REST API client certificates, authenticating clients by certificate common name.")
+	pflag.Float64Var(&cfg.Admission.ClientRate, "rest-api-client-rate", 0, "Execution requests per second allowed per REST API client, by API client or remote IP. 0 disables the limit.")
+	pflag.IntVar(&cfg.Admission.ClientBurst, "rest-api-client-burst", 10, "Execution requests a REST API client can make at once above its rate. Batches larger than the burst are rejected.")
+	pflag.StringSliceVar(&cfg.Admission.TrustedProxies, "rest-api-trusted-proxy", nil, "Network, in CIDR notation, of the proxies trusted to give the REST API client IP in X-Forwarded-For. Without it clients are rate limited by the remote address of their connection. Can be repeated.")
+	pflag.Float64Var(&cfg.Admission.TopicRate, "rest-api-topic-rate", 0, "Execution requests per second allowed per topic. 0 disables the limit.")
+	pflag.IntVar(&cfg.Admission.TopicBurst, "rest-api-topic-burst", 10, "Execution requests a topic can get at once above its rate. Batches with more requests for a topic are rejected.")
+	pflag.IntVar(&cfg.Admission.MaxInFlight, "max-in-flight-executions", 0, "Max number of executions the head runs at once. 0 disables the limit.")
+	pflag.IntVar(&cfg.Admission.MaxQueued, "max-queued-executions", 100, "Max number of executions waiting for an in-flight slot, beyond which requests are rejected.")
+	pflag.DurationVar(&cfg.Admission.QueueTimeout, "execution-queue-timeout", defaultExecutionQueueTimeout, "How long an execution waits for an in-flight slot before its request is rejected. 0 rejects right away.")
+
+	// Tracing configuration.
+	pflag.StringVar(&cfg.TracingConfig.Exporter, "tracing-exporter", TracingExporterNone, "Exporter of OpenTelemetry traces: none, otlp (gRPC) or stdout.")
+	pflag.StringVar(&cfg.TracingConfig.Endpoint, "tracing-endpoint", defaultTracingEndpoint, "Endpoint of the OTLP collector traces are exported to.")
+	pflag.BoolVar(&cfg.TracingConfig.Insecure, "tracing-insecure", false, "Connect to the OTLP collector without TLS.")
+	pflag.Float64Var(&cfg.TracingConfig.SampleRatio, "tracing-sample-ratio", 1.0, "Ratio of requests traced, in the 0-1 range. Workers follow the decision of the head.")
 	pflag.CommandLine.SortFlags = false
 
+	// Secrets passed on the command line are visible to anyone listing processes.
+	_ = pflag.CommandLine.MarkDeprecated("allora-chain-restore-mnemonic", "use --allora-chain-restore-mnemonic-file or "+envRestoreMnemonic+" instead")
+	_ = pflag.CommandLine.MarkDeprecated("allora-chain-account-password", "use --allora-chain-account-password-file or "+envAccountPassphrase+" instead")
+
 	pflag.Parse()
 
 	return &cfg
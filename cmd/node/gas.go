@@ -0,0 +1,328 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cosmosmath "cosmossdk.io/math"
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+)
+
+// Gas strategy kinds.
+const (
+	GasStrategySimulate = "simulate" // simulated gas times a multiplier
+	GasStrategyFixed    = "fixed"    // fixed gas limit, no simulation
+	GasStrategyCapped   = "capped"   // simulated gas times a multiplier, refused above a cap
+)
+
+// Message types gas strategies are configured for.
+const (
+	GasMsgTypeWorker   = "worker"
+	GasMsgTypeReputer  = "reputer"
+	GasMsgTypeRegister = "register"
+	GasMsgTypeStake    = "stake"
+	GasMsgTypeDefault  = "default"
+)
+
+const defaultFeeBudgetWindow = "24h"
+
+var (
+	errFeeBudgetExceeded = errors.New("fee budget exceeded")
+	errGasAboveLimit     = errors.New("gas above limit")
+)
+
+// GasStrategy decides the gas limit of a transaction.
+type GasStrategy struct {
+	Kind       string
+	Multiplier float64 // applied to the simulated gas
+	Limit      uint64  // gas of a fixed strategy, cap of a capped one
+}
+
+// NeedsSimulation tells whether the strategy works on simulated gas.
+func (s GasStrategy) NeedsSimulation() bool {
+	return s.Kind != GasStrategyFixed
+}
+
+// GasLimit returns the gas limit for a transaction that used the given gas in simulation.
+func (s GasStrategy) GasLimit(simulated uint64) (uint64, error) {
+	if s.Kind == GasStrategyFixed {
+		return s.Limit, nil
+	}
+
+	gas := uint64(math.Ceil(float64(simulated)*s.Multiplier)) + simulatedGasMargin
+	if s.Kind == GasStrategyCapped {
+		if simulated > s.Limit {
+			return 0, fmt.Errorf("%w: simulated gas %d is above the cap of %d", errGasAboveLimit, simulated, s.Limit)
+		}
+		gas = min(gas, s.Limit)
+	}
+	return gas, nil
+}
+
+func (s GasStrategy) String() string {
+	switch s.Kind {
+	case GasStrategyFixed:
+		return fmt.Sprintf("%s:%d", s.Kind, s.Limit)
+	case GasStrategyCapped:
+		return fmt.Sprintf("%s:%g:%d", s.Kind, s.Multiplier, s.Limit)
+	default:
+		return fmt.Sprintf("%s:%g", s.Kind, s.Multiplier)
+	}
+}
+
+// ParseGasStrategy parses a strategy of the form simulate[:<multiplier>], fixed:<gas> or capped:<multiplier>:<max gas>.
+func ParseGasStrategy(spec string) (GasStrategy, error) {
+	parts := strings.Split(spec, ":")
+	strategy := GasStrategy{Kind: parts[0], Multiplier: 1}
+
+	var err error
+	switch {
+	case strategy.Kind == GasStrategySimulate && len(parts) <= 2:
+		if len(parts) == 2 {
+			strategy.Multiplier, err = parseGasMultiplier(parts[1])
+		}
+	case strategy.Kind == GasStrategyFixed && len(parts) == 2:
+		strategy.Limit, err = parseGasLimit(parts[1])
+	case strategy.Kind == GasStrategyCapped && len(parts) == 3:
+		strategy.Multiplier, err = parseGasMultiplier(parts[1])
+		if err == nil {
+			strategy.Limit, err = parseGasLimit(parts[2])
+		}
+	default:
+		return GasStrategy{}, fmt.Errorf("invalid gas strategy %q, expected simulate[:<multiplier>], fixed:<gas> or capped:<multiplier>:<max gas>", spec)
+	}
+	if err != nil {
+		return GasStrategy{}, fmt.Errorf("invalid gas strategy %q: %w", spec, err)
+	}
+	return strategy, nil
+}
+
+func parseGasMultiplier(s string) (float64, error) {
+	multiplier, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if multiplier < 1 || math.IsInf(multiplier, 0) {
+		return 0, fmt.Errorf("multiplier must be at least 1, got %s", s)
+	}
+	return multiplier, nil
+}
+
+func parseGasLimit(s string) (uint64, error) {
+	gas, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if gas == 0 {
+		return 0, errors.New("gas must be positive")
+	}
+	return gas, nil
+}
+
+// GasStrategies maps message types to their gas strategy.
+type GasStrategies map[string]GasStrategy
+
+// ParseGasStrategies parses <msg type>=<strategy> pairs. The default strategy, used for message types
+// without one of their own, comes from the legacy gas and gas adjustment settings unless given.
+func ParseGasStrategies(specs []string, gas string, gasAdjustment float64) (GasStrategies, error) {
+	strategies := GasStrategies{}
+
+	if gas != "" && gas != cosmosclient.GasAuto {
+		limit, err := parseGasLimit(gas)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gas value %q: %w", gas, err)
+		}
+		strategies[GasMsgTypeDefault] = GasStrategy{Kind: GasStrategyFixed, Limit: limit}
+	} else {
+		// The gas adjustment used to be ignored below 1, keep it that way.
+		strategies[GasMsgTypeDefault] = GasStrategy{Kind: GasStrategySimulate, Multiplier: max(gasAdjustment, 1)}
+	}
+
+	for _, spec := range specs {
+		msgType, strategySpec, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid gas strategy %q, expected <msg type>=<strategy>", spec)
+		}
+		switch msgType {
+		case GasMsgTypeWorker, GasMsgTypeReputer, GasMsgTypeRegister, GasMsgTypeStake, GasMsgTypeDefault:
+		default:
+			return nil, fmt.Errorf("unknown msg type %q for gas strategy, expected one of %s, %s, %s, %s or %s", msgType,
+				GasMsgTypeWorker, GasMsgTypeReputer, GasMsgTypeRegister, GasMsgTypeStake, GasMsgTypeDefault)
+		}
+
+		strategy, err := ParseGasStrategy(strategySpec)
+		if err != nil {
+			return nil, err
+		}
+		strategies[msgType] = strategy
+	}
+	return strategies, nil
+}
+
+// For returns the strategy for a tx carrying msgs.
+func (s GasStrategies) For(msgs ...sdktypes.Msg) GasStrategy {
+	if strategy, ok := s[gasMsgType(msgs...)]; ok {
+		return strategy
+	}
+	if strategy, ok := s[GasMsgTypeDefault]; ok {
+		return strategy
+	}
+	return GasStrategy{Kind: GasStrategySimulate, Multiplier: 1}
+}
+
+// gasMsgType returns the message type of a tx carrying msgs, decided by its first msg.
+func gasMsgType(msgs ...sdktypes.Msg) string {
+	if len(msgs) == 0 {
+		return GasMsgTypeDefault
+	}
+	switch msgs[0].(type) {
+	case *emissionstypes.MsgInsertBulkWorkerPayload:
+		return GasMsgTypeWorker
+	case *emissionstypes.MsgInsertBulkReputerPayload:
+		return GasMsgTypeReputer
+	case *emissionstypes.MsgRegister:
+		return GasMsgTypeRegister
+	case *emissionstypes.MsgAddStake:
+		return GasMsgTypeStake
+	default:
+		return GasMsgTypeDefault
+	}
+}
+
+// msgsTopic returns the topic of the first msg that has one, for labelling.
+func msgsTopic(msgs ...sdktypes.Msg) string {
+	for _, msg := range msgs {
+		if m, ok := msg.(interface{ GetTopicId() uint64 }); ok {
+			return strconv.FormatUint(m.GetTopicId(), 10)
+		}
+	}
+	return ""
+}
+
+// txFees returns the fees of a tx with the given gas limit at the given gas prices, rounded up.
+func txFees(gasPrices sdktypes.DecCoins, gas uint64) sdktypes.Coins {
+	if gasPrices.IsZero() {
+		return nil
+	}
+	limit := cosmosmath.LegacyNewDecFromInt(cosmosmath.NewIntFromUint64(gas))
+	fees := make(sdktypes.Coins, 0, len(gasPrices))
+	for _, price := range gasPrices {
+		fees = append(fees, sdktypes.NewCoin(price.Denom, price.Amount.Mul(limit).Ceil().RoundInt()))
+	}
+	return sdktypes.NewCoins(fees...)
+}
+
+// FeeBudget caps the fees the node account spends per window, which is either a duration or a
+// number of blocks. Spending is only tracked in memory and starts over when the node restarts.
+type FeeBudget struct {
+	lock   sync.Mutex
+	limit  sdktypes.Coins
+	period time.Duration
+	blocks int64
+
+	window int64 // index of the current window
+	spent  sdktypes.Coins
+
+	now func() time.Time
+}
+
+// ParseFeeBudget parses a budget such as 1000000uallo spent per window, given as a duration (24h)
+// or a number of blocks (720blocks). It returns nil, meaning no budget, for an empty limit.
+func ParseFeeBudget(limit string, window string) (*FeeBudget, error) {
+	if limit == "" {
+		return nil, nil
+	}
+	coins, err := sdktypes.ParseCoinsNormalized(limit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee budget %q: %w", limit, err)
+	}
+	if coins.IsZero() {
+		return nil, fmt.Errorf("invalid fee budget %q: must not be zero", limit)
+	}
+
+	budget := &FeeBudget{limit: coins, now: time.Now}
+	if blocks, ok := strings.CutSuffix(window, "blocks"); ok {
+		budget.blocks, err = strconv.ParseInt(blocks, 10, 64)
+		if err == nil && budget.blocks <= 0 {
+			err = errors.New("must be positive")
+		}
+	} else {
+		budget.period, err = time.ParseDuration(window)
+		if err == nil && budget.period <= 0 {
+			err = errors.New("must be positive")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee budget window %q: %w", window, err)
+	}
+	return budget, nil
+}
+
+// BlockBased tells whether the budget window is counted in blocks, in which case the chain height
+// has to be passed to Reserve.
+func (b *FeeBudget) BlockBased() bool {
+	return b != nil && b.blocks > 0
+}
+
+// Reserve takes fees from the budget of the current window, failing if the budget would be exceeded.
+// The returned func gives the fees back, for txs that were never accepted by the chain.
+func (b *FeeBudget) Reserve(fees sdktypes.Coins, height int64) (func(), error) {
+	if b == nil {
+		return func() {}, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	window := b.windowAt(height)
+	if window != b.window {
+		b.window = window
+		b.spent = nil
+	}
+
+	spent := b.spent.Add(fees...)
+	if !spent.IsAllLTE(b.limit) {
+		return nil, fmt.Errorf("%w: %s spent of %s, tx needs %s", errFeeBudgetExceeded, b.spent, b.limit, fees)
+	}
+	b.spent = spent
+
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if b.window == window {
+			b.spent = b.spent.Sub(fees...)
+		}
+	}, nil
+}
+
+func (b *FeeBudget) windowAt(height int64) int64 {
+	if b.blocks > 0 {
+		return height / b.blocks
+	}
+	return b.now().UnixNano() / int64(b.period)
+}
+
+// CheckGasPrices makes sure the budget applies to the fees paid at the gas prices: without gas prices
+// no fee is computed and the budget would never be charged, and fees in denoms without a budget
+// could never be paid.
+func (b *FeeBudget) CheckGasPrices(gasPrices sdktypes.DecCoins) error {
+	if b == nil {
+		return nil
+	}
+	if gasPrices.IsZero() {
+		return fmt.Errorf("fee budget %s needs gas prices to charge fees against, set --allora-chain-gas-prices", b.limit)
+	}
+	for _, price := range gasPrices {
+		if b.limit.AmountOf(price.Denom).IsZero() {
+			return fmt.Errorf("fee budget %s has no amount for gas price denom %s", b.limit, price.Denom)
+		}
+	}
+	return nil
+}
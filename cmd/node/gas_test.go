@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGasStrategies(t *testing.T) {
+	strategies, err := ParseGasStrategies([]string{"worker=fixed:300000", "reputer=capped:1.5:2000000"}, "auto", 1.2)
+	require.NoError(t, err)
+
+	require.Equal(t, GasStrategy{Kind: GasStrategyFixed, Multiplier: 1, Limit: 300000}, strategies.For(&emissionstypes.MsgInsertBulkWorkerPayload{}))
+	require.Equal(t, GasStrategy{Kind: GasStrategyCapped, Multiplier: 1.5, Limit: 2000000}, strategies.For(&emissionstypes.MsgInsertBulkReputerPayload{}))
+	require.Equal(t, GasStrategy{Kind: GasStrategySimulate, Multiplier: 1.2}, strategies.For(&emissionstypes.MsgRegister{}))
+
+	// A legacy fixed gas value becomes the default strategy.
+	strategies, err = ParseGasStrategies(nil, "500000", 0.1)
+	require.NoError(t, err)
+	require.Equal(t, GasStrategy{Kind: GasStrategyFixed, Limit: 500000}, strategies.For(&emissionstypes.MsgAddStake{}))
+
+	for _, specs := range [][]string{
+		{"worker"},
+		{"miner=simulate"},
+		{"worker=simulate:0.5"},
+		{"worker=fixed"},
+		{"worker=fixed:0"},
+		{"worker=capped:1.5"},
+		{"worker=estimate:2"},
+	} {
+		_, err := ParseGasStrategies(specs, "auto", 1)
+		require.Error(t, err, specs)
+	}
+}
+
+func TestGasStrategyLimit(t *testing.T) {
+	gas, err := GasStrategy{Kind: GasStrategySimulate, Multiplier: 1.5}.GasLimit(100000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(150000+simulatedGasMargin), gas)
+
+	gas, err = GasStrategy{Kind: GasStrategyFixed, Limit: 300000}.GasLimit(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(300000), gas)
+
+	capped := GasStrategy{Kind: GasStrategyCapped, Multiplier: 2, Limit: 250000}
+	gas, err = capped.GasLimit(100000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(220000), gas)
+	gas, err = capped.GasLimit(200000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(250000), gas)
+	_, err = capped.GasLimit(300000)
+	require.Error(t, err)
+}
+
+func TestTxFees(t *testing.T) {
+	prices, err := sdktypes.ParseDecCoins("0.025uallo")
+	require.NoError(t, err)
+
+	require.Equal(t, "7501uallo", txFees(prices, 300001).String())
+	require.Nil(t, txFees(nil, 300000))
+}
+
+func TestFeeBudget(t *testing.T) {
+	budget, err := ParseFeeBudget("1000uallo", "1h")
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	budget.now = func() time.Time { return now }
+
+	fees := sdktypes.NewCoins(sdktypes.NewInt64Coin("uallo", 400))
+	_, err = budget.Reserve(fees, 0)
+	require.NoError(t, err)
+	release, err := budget.Reserve(fees, 0)
+	require.NoError(t, err)
+	_, err = budget.Reserve(fees, 0)
+	require.ErrorIs(t, err, errFeeBudgetExceeded)
+
+	// Fees of txs that never made it to the chain are given back.
+	release()
+	_, err = budget.Reserve(fees, 0)
+	require.NoError(t, err)
+
+	// The budget starts over in the next window.
+	now = now.Add(time.Hour)
+	_, err = budget.Reserve(fees, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, budget.CheckGasPrices(sdktypes.NewDecCoins(sdktypes.NewInt64DecCoin("uallo", 1))))
+	require.Error(t, budget.CheckGasPrices(sdktypes.NewDecCoins(sdktypes.NewInt64DecCoin("stake", 1))))
+	// Without gas prices no fee would ever be charged against the budget.
+	require.Error(t, budget.CheckGasPrices(nil))
+	require.Error(t, budget.CheckGasPrices(sdktypes.NewDecCoins()))
+
+	var noBudget *FeeBudget
+	require.NoError(t, noBudget.CheckGasPrices(nil))
+}
+
+func TestFeeBudgetBlocks(t *testing.T) {
+	budget, err := ParseFeeBudget("1000uallo", "100blocks")
+	require.NoError(t, err)
+	require.True(t, budget.BlockBased())
+
+	fees := sdktypes.NewCoins(sdktypes.NewInt64Coin("uallo", 600))
+	_, err = budget.Reserve(fees, 150)
+	require.NoError(t, err)
+	_, err = budget.Reserve(fees, 199)
+	require.ErrorIs(t, err, errFeeBudgetExceeded)
+	_, err = budget.Reserve(fees, 200)
+	require.NoError(t, err)
+
+	for _, window := range []string{"0blocks", "xblocks", "day", "-1h"} {
+		_, err = ParseFeeBudget("1000uallo", window)
+		require.Error(t, err, window)
+	}
+
+	// No budget at all.
+	budget, err = ParseFeeBudget("", defaultFeeBudgetWindow)
+	require.NoError(t, err)
+	require.Nil(t, budget)
+	_, err = budget.Reserve(fees, 0)
+	require.NoError(t, err)
+}
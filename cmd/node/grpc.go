@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/allora-network/allora-inference-base/pkg/headapi/headv1"
+	"github.com/allora-network/b7s/api"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const functionInstallTimeout = 10 * time.Second
+
+// headGRPCServer serves the gRPC API of the head. It shares the execution, authentication and
+// admission logic of the REST API.
+type headGRPCServer struct {
+	headv1.UnimplementedHeadServiceServer
+
+	api       api.API
+	history   *HistoryStore
+	auth      *APIAuth
+	admission *Admission
+}
+
+// newGRPCServer creates the gRPC server of the head, serving TLS with the REST API TLS config if set.
+func newGRPCServer(a api.API, history *HistoryStore, auth *APIAuth, admission *Admission, tlsConfig *tls.Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	headv1.RegisterHeadServiceServer(server, &headGRPCServer{
+		api:       a,
+		history:   history,
+		auth:      auth,
+		admission: admission,
+	})
+	reflection.Register(server)
+	return server
+}
+
+// guard authenticates and authorizes the caller. API keys are read from the authorization or
+// x-api-key metadata, HMAC signatures are REST only. It returns the client executions are rate
+// limited by: the authenticated API client, or the remote IP without authentication.
+func (s *headGRPCServer) guard(ctx context.Context, permission string, topics ...string) (string, error) {
+	var client string
+	if s.auth != nil {
+		c, err := s.authenticate(ctx)
+		if err != nil {
+			return "", status.Error(grpccodes.Unauthenticated, err.Error())
+		}
+		err = c.authorize(permission, topics)
+		if err != nil {
+			return "", status.Error(grpccodes.PermissionDenied, err.Error())
+		}
+		client = c.Name
+	}
+	if client == "" {
+		if p, ok := grpcpeer.FromContext(ctx); ok {
+			client, _, _ = net.SplitHostPort(p.Addr.String())
+		}
+	}
+	return client, nil
+}
+
+// admit rate limits the executions of the client. Like the REST API, requests are only admitted once
+// validated, so that invalid requests take no token.
+func (s *headGRPCServer) admit(client string, topics ...string) error {
+	reason, delay, ok := s.admission.allow(client, topics)
+	if !ok {
+		admissionRejected.WithLabelValues(reason).Inc()
+		return status.Errorf(grpccodes.ResourceExhausted, "rate limit exceeded (%s), retry in %s", reason, delay.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *headGRPCServer) authenticate(ctx context.Context) (*APIClient, error) {
+	if p, ok := grpcpeer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if client := s.auth.clientByCert(&info.State); client != nil {
+				return client, nil
+			}
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return s.auth.clientByKey(first("authorization"), first("x-api-key"))
+}
+
+// validateGRPCRequest checks a request against the OpenAPI spec of its REST counterpart.
+func validateGRPCRequest(path string, req any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return status.Error(grpccodes.InvalidArgument, err.Error())
+	}
+	err = headapi.ValidateRequest(http.MethodPost, path, body)
+	var validationErr *headapi.ValidationError
+	if errors.As(err, &validationErr) {
+		return status.Error(grpccodes.InvalidArgument, "invalid request: "+validationErr.Error())
+	}
+	if err != nil {
+		return status.Error(grpccodes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (s *headGRPCServer) prepareExecution(ctx context.Context, pbReq *headv1.ExecuteRequest) (ExecuteRequest, func(), error) {
+	req := executeRequestFromProto(pbReq)
+	client, err := s.guard(ctx, PermissionExecute, req.Topic)
+	if err != nil {
+		return req, nil, err
+	}
+	err = validateGRPCRequest(headapi.PathExecute, req)
+	if err != nil {
+		return req, nil, err
+	}
+	err = s.admit(client, req.Topic)
+	if err != nil {
+		return req, nil, err
+	}
+	release, err := s.admission.Acquire(ctx)
+	if err != nil {
+		return req, nil, status.Error(grpccodes.ResourceExhausted, err.Error())
+	}
+	return req, release, nil
+}
+
+// Execute executes a function like the REST execute endpoint.
+func (s *headGRPCServer) Execute(ctx context.Context, pbReq *headv1.ExecuteRequest) (*headv1.ExecuteResponse, error) {
+	req, release, err := s.prepareExecution(ctx, pbReq)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, _ := executeRequest(ctx, s.api, s.history, req, nil)
+	return executeResponseToProto(res), nil
+}
+
+// ExecuteStream executes a function and streams its phases until the last one.
+func (s *headGRPCServer) ExecuteStream(pbReq *headv1.ExecuteRequest, stream headv1.HeadService_ExecuteStreamServer) error {
+	req, release, err := s.prepareExecution(stream.Context(), pbReq)
+	if err != nil {
+		return err
+	}
+
+	// Room for every phase, so that reports never wait for the stream.
+	progress := make(chan ExecutionProgress, 8)
+	go func() {
+		defer release()
+		followExecution(stream.Context(), s.api, s.history, req, func(p ExecutionProgress) {
+			progress <- p
+		})
+	}()
+
+	for {
+		select {
+		case p := <-progress:
+			err := stream.Send(executeProgressToProto(p))
+			if err != nil {
+				return err
+			}
+			if p.Done {
+				return nil
+			}
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// Install installs a function like the REST install endpoint.
+func (s *headGRPCServer) Install(ctx context.Context, pbReq *headv1.InstallRequest) (*headv1.InstallResponse, error) {
+	_, err := s.guard(ctx, PermissionInstall)
+	if err != nil {
+		return nil, err
+	}
+	req := headapi.InstallRequest{CID: pbReq.GetCid(), URI: pbReq.GetUri(), Subgroup: pbReq.GetSubgroup()}
+	err = validateGRPCRequest(headapi.PathInstall, req)
+	if err != nil {
+		return nil, err
+	}
+
+	installCtx, cancel := context.WithTimeout(ctx, functionInstallTimeout)
+	defer cancel()
+	err = s.api.Node.PublishFunctionInstall(installCtx, req.URI, req.CID, req.Subgroup)
+	// Like the REST endpoint, a timed out install is reported by its code.
+	if errors.Is(installCtx.Err(), context.DeadlineExceeded) {
+		return &headv1.InstallResponse{Code: strconv.Itoa(http.StatusRequestTimeout)}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(grpccodes.Internal, "function installation failed: %s", err)
+	}
+	return &headv1.InstallResponse{Code: strconv.Itoa(http.StatusOK)}, nil
+}
+
+// ExecutionResult returns the result of an execution like the REST result endpoint.
+func (s *headGRPCServer) ExecutionResult(ctx context.Context, pbReq *headv1.ExecutionResultRequest) (*headv1.ExecutionResultResponse, error) {
+	_, err := s.guard(ctx, PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if pbReq.GetId() == "" {
+		return nil, status.Error(grpccodes.InvalidArgument, "missing request ID")
+	}
+	res, ok := executionResult(s.api.Node, pbReq.GetId())
+	if !ok {
+		return nil, status.Error(grpccodes.NotFound, "no result for the request")
+	}
+	return &headv1.ExecutionResultResponse{
+		Code:      res.Code.String(),
+		Result:    runtimeOutputToProto(res.Result),
+		RequestId: res.RequestID,
+		Usage: &headv1.Usage{
+			WallClockTime: int64(res.Usage.WallClockTime),
+			CpuUserTime:   int64(res.Usage.CPUUserTime),
+			CpuSysTime:    int64(res.Usage.CPUSysTime),
+			MemoryMaxKb:   res.Usage.MemoryMaxKB,
+		},
+	}, nil
+}
+
+// executionResult looks up the result of a request. b7s panics on unknown requests.
+func executionResult(node api.Node, id string) (res execute.Result, ok bool) {
+	defer func() {
+		if recover() != nil {
+			res, ok = execute.Result{}, false
+		}
+	}()
+	return node.ExecutionResult(id)
+}
+
+// Health checks that the head is up. It needs no authentication.
+func (s *headGRPCServer) Health(context.Context, *headv1.HealthRequest) (*headv1.HealthResponse, error) {
+	return &headv1.HealthResponse{Code: http.StatusOK}, nil
+}
+
+func executeRequestFromProto(pb *headv1.ExecuteRequest) ExecuteRequest {
+	req := ExecuteRequest{
+		Request: execute.Request{
+			FunctionID: pb.GetFunctionId(),
+			Method:     pb.GetMethod(),
+			Parameters: parametersFromProto(pb.GetParameters()),
+			Signature:  pb.GetSignature(),
+		},
+		Topic: pb.GetTopic(),
+	}
+	cfg := pb.GetConfig()
+	if cfg == nil {
+		return req
+	}
+	runtime := cfg.GetRuntime()
+	req.Config = execute.Config{
+		Runtime: execute.BLSRuntimeConfig{
+			Entry:           runtime.GetEntry(),
+			ExecutionTime:   runtime.GetRunTime(),
+			DebugInfo:       runtime.GetDebugInfo(),
+			Fuel:            runtime.GetLimitedFuel(),
+			Memory:          runtime.GetLimitedMemory(),
+			Logger:          runtime.GetRuntimeLogger(),
+			DriversRootPath: runtime.GetDriversRootPath(),
+		},
+		Stdin:       cfg.Stdin,
+		Permissions: cfg.GetPermissions(),
+		ResultAggregation: execute.ResultAggregation{
+			Enable:     cfg.GetResultAggregation().GetEnable(),
+			Type:       cfg.GetResultAggregation().GetType(),
+			Parameters: parametersFromProto(cfg.GetResultAggregation().GetParameters()),
+		},
+		NodeCount:          int(cfg.GetNumberOfNodes()),
+		Timeout:            int(cfg.GetTimeout()),
+		ConsensusAlgorithm: cfg.GetConsensusAlgorithm(),
+		Threshold:          cfg.GetThreshold(),
+	}
+	for _, env := range cfg.GetEnvVars() {
+		req.Config.Environment = append(req.Config.Environment, execute.EnvVar{Name: env.GetName(), Value: env.GetValue()})
+	}
+	if attributes := cfg.GetAttributes(); attributes != nil {
+		req.Config.Attributes = &execute.Attributes{
+			Values:              parametersFromProto(attributes.GetValues()),
+			AttestationRequired: attributes.GetAttestationRequired(),
+		}
+	}
+	return req
+}
+
+func parametersFromProto(pb []*headv1.Parameter) []execute.Parameter {
+	if len(pb) == 0 {
+		return nil
+	}
+	params := make([]execute.Parameter, len(pb))
+	for i, p := range pb {
+		params[i] = execute.Parameter{Name: p.GetName(), Value: p.GetValue()}
+	}
+	return params
+}
+
+func executeResponseToProto(res ExecuteResponse) *headv1.ExecuteResponse {
+	pb := &headv1.ExecuteResponse{
+		Code:      res.Code.String(),
+		RequestId: res.RequestID,
+		Message:   res.Message,
+		Cluster: &headv1.Cluster{
+			Main:  peerIDString(res.Cluster.Main),
+			Peers: peerIDStrings(res.Cluster.Peers),
+		},
+	}
+	for _, result := range res.Results {
+		pb.Results = append(pb.Results, &headv1.AggregatedResult{
+			Result:    runtimeOutputToProto(result.Result),
+			Peers:     peerIDStrings(result.Peers),
+			Frequency: result.Frequency,
+		})
+	}
+	if res.Error != nil {
+		pb.Error = &headv1.ExecutionError{Code: res.Error.Code, Message: res.Error.Message}
+		for _, f := range res.Error.Peers {
+			pb.Error.Peers = append(pb.Error.Peers, &headv1.PeerFailure{
+				Peer:     f.Peer,
+				Code:     f.Code.String(),
+				ExitCode: int64(f.ExitCode),
+				Stderr:   f.Stderr,
+			})
+		}
+	}
+	return pb
+}
+
+func executeProgressToProto(p ExecutionProgress) *headv1.ExecuteProgress {
+	pb := &headv1.ExecuteProgress{Phase: p.Phase, Done: p.Done}
+	if p.Response != nil {
+		pb.Response = executeResponseToProto(*p.Response)
+	}
+	if p.Submission != nil {
+		pb.Submission = &headv1.ChainSubmission{
+			Status:    p.Submission.Status,
+			TxHashes:  p.Submission.TxHashes,
+			Height:    p.Submission.Height,
+			Error:     p.Submission.Error,
+			ErrorCode: p.Submission.ErrorCode,
+			UpdatedAt: p.Submission.UpdatedAt.UnixNano(),
+		}
+	}
+	return pb
+}
+
+func runtimeOutputToProto(out execute.RuntimeOutput) *headv1.RuntimeOutput {
+	return &headv1.RuntimeOutput{Stdout: out.Stdout, Stderr: out.Stderr, ExitCode: int64(out.ExitCode)}
+}
+
+func peerIDString(id peer.ID) string {
+	if id == "" {
+		return ""
+	}
+	return id.String()
+}
+
+func peerIDStrings(ids []peer.ID) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = peerIDString(id)
+	}
+	return strs
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi/headv1"
+	"github.com/allora-network/b7s/api"
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeNode struct {
+	results map[string]execute.Result
+}
+
+func (n *fakeNode) ExecuteFunction(ctx context.Context, req execute.Request, subgroup string) (codes.Code, string, execute.ResultMap, execute.Cluster, error) {
+	results := execute.ResultMap{
+		"peer": {Code: codes.OK, Result: execute.RuntimeOutput{Stdout: `{"value":"1"}`}},
+	}
+	return codes.OK, "request-" + subgroup, results, execute.Cluster{Peers: []peer.ID{"peer"}}, nil
+}
+
+func (n *fakeNode) ExecutionResult(id string) (execute.Result, bool) {
+	res, ok := n.results[id]
+	return res, ok
+}
+
+func (n *fakeNode) PublishFunctionInstall(ctx context.Context, uri string, cid string, subgroup string) error {
+	return nil
+}
+
+func TestGRPCServer(t *testing.T) {
+	auth, err := NewAPIAuth([]APIClient{
+		{Name: "executor", APIKey: NewSecret([]byte("exec-key")), Permissions: []string{PermissionExecute}, Topics: []string{"1"}},
+	})
+	require.NoError(t, err)
+	// Tokens for the two valid executions below, which invalid requests must not take.
+	admission, err := NewAdmission(AdmissionConfig{ClientRate: 0.001, ClientBurst: 2})
+	require.NoError(t, err)
+	node := &fakeNode{results: map[string]execute.Result{}}
+	server := newGRPCServer(*api.New(zerolog.Nop(), node), nil, auth, admission, nil)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := headv1.NewHeadServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer exec-key")
+	req := &headv1.ExecuteRequest{FunctionId: "bafy", Method: "main.wasm", Topic: "1"}
+
+	_, err = client.Execute(context.Background(), req)
+	require.Equal(t, grpccodes.Unauthenticated, status.Code(err))
+	_, err = client.Execute(ctx, &headv1.ExecuteRequest{FunctionId: "bafy", Method: "main.wasm", Topic: "2"})
+	require.Equal(t, grpccodes.PermissionDenied, status.Code(err))
+	_, err = client.Execute(ctx, &headv1.ExecuteRequest{FunctionId: "bafy", Topic: "1"})
+	require.Equal(t, grpccodes.InvalidArgument, status.Code(err))
+	_, err = client.Install(ctx, &headv1.InstallRequest{Cid: "bafy"})
+	require.Equal(t, grpccodes.PermissionDenied, status.Code(err))
+
+	res, err := client.Execute(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, codes.OK.String(), res.Code)
+	require.Equal(t, "request-allora-topic-1-worker", res.RequestId)
+	require.Len(t, res.Results, 1)
+	require.Equal(t, `{"value":"1"}`, res.Results[0].Result.Stdout)
+
+	// Without submission tracking, the stream ends with the aggregated results.
+	stream, err := client.ExecuteStream(ctx, req)
+	require.NoError(t, err)
+	var phases []string
+	for {
+		progress, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		phases = append(phases, progress.Phase)
+		if progress.Done {
+			require.Equal(t, codes.OK.String(), progress.Response.Code)
+		}
+	}
+	require.Equal(t, []string{AsyncPhaseRollCall, AsyncPhaseAggregated}, phases)
+	_, err = client.Execute(ctx, req)
+	require.Equal(t, grpccodes.ResourceExhausted, status.Code(err))
+
+	health, err := client.Health(context.Background(), &headv1.HealthRequest{})
+	require.NoError(t, err)
+	require.EqualValues(t, 200, health.Code)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/allora-network/b7s/node/aggregate"
+	"github.com/cockroachdb/pebble"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultHistoryDB   = "history-db"
+	defaultHistorySize = 10000
+
+	defaultHistoryListLimit = 100
+	maxHistoryListLimit     = 1000
+)
+
+// Key prefixes of the history database. Records are stored by request ID and indexed by the time
+// they were recorded, so that they can be listed newest first and pruned oldest first.
+const (
+	historyRecordPrefix = "r/"
+	historyTimePrefix   = "t/"
+)
+
+// ExecutionRecord is what the head keeps of an execution request once it has been answered.
+type ExecutionRecord struct {
+	RequestID   string            `json:"request_id"`
+	Topic       string            `json:"topic"`
+	FunctionID  string            `json:"function_id"`
+	Method      string            `json:"method"`
+	Environment []execute.EnvVar  `json:"environment,omitempty"` // redacted
+	Nonce       int64             `json:"nonce,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	Code        codes.Code        `json:"code"`
+	Message     string            `json:"message,omitempty"`
+	ErrorCode   string            `json:"error_code,omitempty"`
+	Cluster     execute.Cluster   `json:"cluster"`
+	Outputs     []PeerOutput      `json:"outputs,omitempty"`
+	Results     aggregate.Results `json:"results,omitempty"`
+	Submission  *ChainSubmission  `json:"submission,omitempty"`
+}
+
+// PeerOutput is the output of the function on a single peer.
+type PeerOutput struct {
+	Peer     string     `json:"peer"`
+	Code     codes.Code `json:"code"`
+	Stdout   string     `json:"stdout"`
+	Stderr   string     `json:"stderr,omitempty"`
+	ExitCode int        `json:"exit_code"`
+}
+
+// peerOutputs lists the results of the peers sorted by peer ID.
+func peerOutputs(results execute.ResultMap) []PeerOutput {
+	outputs := make([]PeerOutput, 0, len(results))
+	for peer, res := range results {
+		outputs = append(outputs, PeerOutput{
+			Peer:     peer.String(),
+			Code:     res.Code,
+			Stdout:   res.Result.Stdout,
+			Stderr:   res.Result.Stderr,
+			ExitCode: res.Result.ExitCode,
+		})
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		return outputs[i].Peer < outputs[j].Peer
+	})
+	return outputs
+}
+
+// HistoryFilter selects the records returned by HistoryStore.List. Zero fields match everything.
+type HistoryFilter struct {
+	Topic      string
+	FunctionID string
+	Code       codes.Code
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+func (f HistoryFilter) match(rec ExecutionRecord) bool {
+	switch {
+	case f.Topic != "" && rec.Topic != f.Topic:
+		return false
+	case f.FunctionID != "" && rec.FunctionID != f.FunctionID:
+		return false
+	case f.Code != "" && rec.Code != f.Code:
+		return false
+	case !f.Since.IsZero() && rec.StartedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !rec.StartedAt.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// HistoryStore persists the latest executions of the head in a pebble database. Only the given
+// number of records is kept, the oldest ones are dropped first. A nil store records nothing.
+type HistoryStore struct {
+	lock  sync.Mutex
+	db    *pebble.DB
+	size  int
+	count int
+
+	// Looks up the chain submissions of recorded executions, if set.
+	submissions *SubmissionTracker
+	log         zerolog.Logger
+}
+
+// OpenHistoryStore opens the history database at path, keeping at most size records.
+func OpenHistoryStore(path string, size int, log zerolog.Logger) (*HistoryStore, error) {
+	db, err := pebble.Open(path, &pebble.Options{Logger: &pebbleNoopLogger{}})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &HistoryStore{db: db, size: size, log: log}
+	it, err := db.NewIter(prefixIterOptions(historyTimePrefix))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for it.First(); it.Valid(); it.Next() {
+		s.count++
+	}
+	if err := it.Close(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *HistoryStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Record stores a new execution and starts looking up its chain submission, which is passed to
+// onSubmission, if set, once known. It returns whether the submission is looked up.
+func (s *HistoryStore) Record(rec ExecutionRecord, onSubmission func(ChainSubmission)) bool {
+	if s == nil {
+		return false
+	}
+	// b7s returns no request ID for executions failing before the roll call is done, which would
+	// otherwise all be stored under the same key.
+	if rec.RequestID == "" {
+		rec.RequestID = uuid.NewString()
+	}
+	topicId, mode, track := uint64(0), "", false
+	if s.submissions != nil {
+		topicId, mode, track = s.submissions.Trackable(rec)
+	}
+	if track {
+		rec.Submission = &ChainSubmission{Status: SubmissionPending, UpdatedAt: time.Now()}
+	}
+
+	err := s.Put(rec)
+	if err != nil {
+		s.log.Warn().Err(err).Str("request", rec.RequestID).Msg("could not record execution")
+		return false
+	}
+
+	if track {
+		s.submissions.Track(rec.RequestID, topicId, mode, rec.Nonce, func(sub ChainSubmission) {
+			err := s.Update(rec.RequestID, func(r *ExecutionRecord) {
+				r.Submission = &sub
+			})
+			if err != nil {
+				s.log.Warn().Err(err).Str("request", rec.RequestID).Msg("could not record chain submission")
+			}
+			if onSubmission != nil {
+				onSubmission(sub)
+			}
+		})
+	}
+	return track
+}
+
+// Put stores the record, replacing any record with the same request ID.
+func (s *HistoryStore) Put(rec ExecutionRecord) error {
+	if rec.RequestID == "" {
+		return errors.New("execution record has no request ID")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, exists, err := s.get(rec.RequestID)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("could not encode execution record: %w", err)
+	}
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(historyRecordKey(rec.RequestID), value, nil); err != nil {
+		return err
+	}
+	if !exists {
+		if err := batch.Set(historyTimeKey(rec.StartedAt, rec.RequestID), []byte(rec.RequestID), nil); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	if !exists {
+		s.count++
+	}
+	return s.prune()
+}
+
+// Update applies fn to the record of the request, if it is still kept.
+func (s *HistoryStore) Update(requestID string, fn func(rec *ExecutionRecord)) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rec, ok, err := s.get(requestID)
+	if err != nil || !ok {
+		return err
+	}
+	fn(&rec)
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("could not encode execution record: %w", err)
+	}
+	return s.db.Set(historyRecordKey(requestID), value, pebble.Sync)
+}
+
+// Get returns the record of the request.
+func (s *HistoryStore) Get(requestID string) (ExecutionRecord, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.get(requestID)
+}
+
+func (s *HistoryStore) get(requestID string) (ExecutionRecord, bool, error) {
+	value, closer, err := s.db.Get(historyRecordKey(requestID))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return ExecutionRecord{}, false, nil
+	}
+	if err != nil {
+		return ExecutionRecord{}, false, err
+	}
+	defer closer.Close()
+
+	var rec ExecutionRecord
+	err = json.Unmarshal(value, &rec)
+	if err != nil {
+		return ExecutionRecord{}, false, fmt.Errorf("could not decode execution record: %w", err)
+	}
+	return rec, true, nil
+}
+
+// List returns the records matching the filter, newest first.
+func (s *HistoryStore) List(filter HistoryFilter) ([]ExecutionRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultHistoryListLimit
+	}
+
+	it, err := s.db.NewIter(prefixIterOptions(historyTimePrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	records := make([]ExecutionRecord, 0)
+	for it.Last(); it.Valid() && len(records) < limit; it.Prev() {
+		rec, ok, err := s.get(string(it.Value()))
+		if err != nil {
+			return nil, err
+		}
+		if ok && filter.match(rec) {
+			records = append(records, rec)
+		}
+	}
+	return records, it.Error()
+}
+
+// prune drops the oldest records beyond the size of the store.
+func (s *HistoryStore) prune() error {
+	if s.count <= s.size {
+		return nil
+	}
+
+	it, err := s.db.NewIter(prefixIterOptions(historyTimePrefix))
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	dropped := 0
+	for it.First(); it.Valid() && s.count-dropped > s.size; it.Next() {
+		_ = batch.Delete(append([]byte(nil), it.Key()...), nil)
+		_ = batch.Delete(historyRecordKey(string(it.Value())), nil)
+		dropped++
+	}
+	if err := it.Close(); err != nil {
+		return err
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	s.count -= dropped
+	return nil
+}
+
+func historyRecordKey(requestID string) []byte {
+	return []byte(historyRecordPrefix + requestID)
+}
+
+// historyTimeKey sorts by time, the request ID keeps keys of the same instant apart.
+func historyTimeKey(t time.Time, requestID string) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", historyTimePrefix, t.UnixNano(), requestID))
+}
+
+func prefixIterOptions(prefix string) *pebble.IterOptions {
+	upper := []byte(prefix)
+	upper[len(upper)-1]++
+	return &pebble.IterOptions{LowerBound: []byte(prefix), UpperBound: upper}
+}
+
+// listExecutions serves the recorded executions, filtered by the topic, function, code, since and
+// until (RFC 3339) query parameters, newest first and at most limit of them.
+func listExecutions(history *HistoryStore) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if history == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "execution history is disabled")
+		}
+
+		filter := HistoryFilter{
+			Topic:      ctx.QueryParam("topic"),
+			FunctionID: ctx.QueryParam("function"),
+			Code:       codes.Code(ctx.QueryParam("code")),
+			Limit:      defaultHistoryListLimit,
+		}
+		var err error
+		if v := ctx.QueryParam("since"); v != "" {
+			filter.Since, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid since: %s", err))
+			}
+		}
+		if v := ctx.QueryParam("until"); v != "" {
+			filter.Until, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid until: %s", err))
+			}
+		}
+		if v := ctx.QueryParam("limit"); v != "" {
+			filter.Limit, err = strconv.Atoi(v)
+			if err != nil || filter.Limit <= 0 || filter.Limit > maxHistoryListLimit {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxHistoryListLimit))
+			}
+		}
+
+		records, err := history.List(filter)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("could not list executions: %w", err))
+		}
+		return ctx.JSON(http.StatusOK, records)
+	}
+}
+
+// getExecution serves the recorded execution of a request.
+func getExecution(history *HistoryStore) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if history == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "execution history is disabled")
+		}
+
+		rec, ok, err := history.Get(ctx.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("could not get execution: %w", err))
+		}
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, "execution not found")
+		}
+		return ctx.JSON(http.StatusOK, rec)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryStore(t *testing.T) {
+	history, err := OpenHistoryStore(t.TempDir(), 3, zerolog.Nop())
+	require.NoError(t, err)
+	defer history.Close()
+
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i, id := range []string{"a", "b", "c", "d"} {
+		topic := "1"
+		if i%2 == 1 {
+			topic = "1/reputer"
+		}
+		require.NoError(t, history.Put(ExecutionRecord{RequestID: id, Topic: topic, Code: codes.OK, StartedAt: start.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	// The oldest record is dropped.
+	_, ok, err := history.Get("a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	records, err := history.List(HistoryFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "c", "b"}, requestIDs(records))
+
+	records, err = history.List(HistoryFilter{Topic: "1/reputer"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "b"}, requestIDs(records))
+
+	records, err = history.List(HistoryFilter{Since: start.Add(2 * time.Minute), Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"d"}, requestIDs(records))
+
+	// Updates keep the record in place.
+	require.NoError(t, history.Update("c", func(rec *ExecutionRecord) {
+		rec.Submission = &ChainSubmission{Status: SubmissionConfirmed, TxHashes: []string{"ABC"}}
+	}))
+	rec, ok, err := history.Get("c")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, SubmissionConfirmed, rec.Submission.Status)
+	records, err = history.List(HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+}
+
+func TestHistoryStoreWithoutRequestID(t *testing.T) {
+	history, err := OpenHistoryStore(t.TempDir(), 10, zerolog.Nop())
+	require.NoError(t, err)
+	defer history.Close()
+
+	require.Error(t, history.Put(ExecutionRecord{Topic: "1"}))
+
+	// Executions without a request ID do not overwrite each other.
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	history.Record(ExecutionRecord{Topic: "1", Code: codes.Timeout, StartedAt: start}, nil)
+	history.Record(ExecutionRecord{Topic: "2", Code: codes.Timeout, StartedAt: start.Add(time.Minute)}, nil)
+	records, err := history.List(HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.NotEmpty(t, records[0].RequestID)
+	require.NotEqual(t, records[0].RequestID, records[1].RequestID)
+}
+
+func requestIDs(records []ExecutionRecord) []string {
+	ids := make([]string, len(records))
+	for i, rec := range records {
+		ids[i] = rec.RequestID
+	}
+	return ids
+}
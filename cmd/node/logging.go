@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Log formats.
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// Subsystems that can be given their own log level.
+const (
+	LogSubsystemExecutor = "executor"
+	LogSubsystemAppChain = "appchain"
+	LogSubsystemAPI      = "api"
+	LogSubsystemP2P      = "p2p"
+)
+
+var logSubsystems = []string{LogSubsystemExecutor, LogSubsystemAppChain, LogSubsystemAPI, LogSubsystemP2P}
+
+// newLogger creates the root logger, writing human readable lines or JSON objects to w.
+func newLogger(format string, w io.Writer) (zerolog.Logger, error) {
+	switch format {
+	case LogFormatConsole, "":
+		return zerolog.New(zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}).With().Timestamp().Logger(), nil
+	case LogFormatJSON:
+		return zerolog.New(w).With().Timestamp().Logger(), nil
+	default:
+		return zerolog.Nop(), fmt.Errorf("unknown log format %q, expected %s or %s", format, LogFormatConsole, LogFormatJSON)
+	}
+}
+
+// parseLogLevels parses the log levels of subsystems, given by subsystem name.
+func parseLogLevels(levels map[string]string) (map[string]zerolog.Level, error) {
+	parsed := make(map[string]zerolog.Level, len(levels))
+	for subsystem, level := range levels {
+		if !isLogSubsystem(subsystem) {
+			return nil, fmt.Errorf("unknown log subsystem %q, expected one of %s", subsystem, strings.Join(logSubsystems, ", "))
+		}
+		lvl, err := zerolog.ParseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level for %s: %w", subsystem, err)
+		}
+		parsed[subsystem] = lvl
+	}
+	return parsed, nil
+}
+
+func isLogSubsystem(name string) bool {
+	for _, subsystem := range logSubsystems {
+		if name == subsystem {
+			return true
+		}
+	}
+	return false
+}
+
+// subsystemLogger derives the logger of a subsystem from the root logger, using the subsystem's own
+// level if it has one and the root level otherwise.
+func subsystemLogger(root zerolog.Logger, subsystem string, levels map[string]zerolog.Level) zerolog.Logger {
+	log := root.With().Str("subsystem", subsystem).Logger()
+	if level, ok := levels[subsystem]; ok {
+		log = log.Level(level)
+	}
+	return log
+}
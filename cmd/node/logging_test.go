@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubsystemLogger(t *testing.T) {
+	var buf bytes.Buffer
+	root, err := newLogger(LogFormatJSON, &buf)
+	require.NoError(t, err)
+	root = root.Level(zerolog.InfoLevel)
+
+	levels, err := parseLogLevels(map[string]string{LogSubsystemExecutor: "debug", LogSubsystemP2P: "warn"})
+	require.NoError(t, err)
+
+	executorLog := subsystemLogger(root, LogSubsystemExecutor, levels)
+	p2pLog := subsystemLogger(root, LogSubsystemP2P, levels)
+	apiLog := subsystemLogger(root, LogSubsystemAPI, levels)
+
+	executorLog.Debug().Msg("executor debug")
+	p2pLog.Info().Msg("p2p info")
+	apiLog.Debug().Msg("api debug")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "executor debug", entry["message"])
+	require.Equal(t, LogSubsystemExecutor, entry["subsystem"])
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestLoggingConfigErrors(t *testing.T) {
+	_, err := newLogger("xml", &bytes.Buffer{})
+	require.Error(t, err)
+
+	_, err = parseLogLevels(map[string]string{"chain": "debug"})
+	require.Error(t, err)
+
+	_, err = parseLogLevels(map[string]string{LogSubsystemAPI: "loud"})
+	require.Error(t, err)
+}
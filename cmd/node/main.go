@@ -6,20 +6,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/cockroachdb/pebble"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
 	"github.com/ziflex/lecho/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	alloraMath "github.com/allora-network/allora-chain/math"
 	"github.com/allora-network/allora-chain/x/emissions/types"
@@ -34,7 +40,6 @@ import (
 	"github.com/allora-network/b7s/peerstore"
 	"github.com/allora-network/b7s/store"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
@@ -44,34 +49,127 @@ const (
 )
 
 var (
-	opsProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+	opsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_node_total_operations",
 		Help: "The total number of processed operations",
-	})
+	}, []string{"topic", "mode", "outcome"})
 
-	headRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	headRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_head_node_total_requests",
 		Help: "The total number of request made by head node",
-	})
+	}, []string{"topic", "mode", "outcome"})
 
-	workerResponse = prometheus.NewCounter(prometheus.CounterOpts{
+	workerResponse = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_worker_node_total_response",
 		Help: "The total number of responds from worker node",
-	})
+	}, []string{"topic"})
 
-	reputerResponse = prometheus.NewCounter(prometheus.CounterOpts{
+	reputerResponse = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_reputer_node_total_response",
 		Help: "The total number of responds from reputer node",
-	})
+	}, []string{"topic"})
 
-	workerChainCommit = prometheus.NewCounter(prometheus.CounterOpts{
+	workerChainCommit = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_worker_node_chain_commit",
-		Help: "The total number of worker commits to the chain",
-	})
+		Help: "The total number of worker commits to the chain, by whether all, some or none of the bundles were submitted",
+	}, []string{"topic", "outcome"})
 
-	reputerChainCommit = prometheus.NewCounter(prometheus.CounterOpts{
+	reputerChainCommit = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "allora_reputer_node_chain_commit",
-		Help: "The total number of reputer commits to the chain",
+		Help: "The total number of reputer commits to the chain, by whether all, some or none of the bundles were submitted",
+	}, []string{"topic", "outcome"})
+
+	executionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "allora_execution_duration_seconds",
+		Help:    "The time taken by function executions, on the head from request to aggregated result and on workers by the WASM function",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"role", "topic", "mode", "outcome"})
+
+	wasmExitCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_wasm_exit_codes_total",
+		Help: "The exit codes of WASM function executions on the worker",
+	}, []string{"topic", "mode", "exit_code"})
+
+	valuesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_worker_values_dropped_total",
+		Help: "The loss values left out of worker and reputer bundles because they failed to transform",
+	}, []string{"topic", "mode"})
+
+	topicEnvMismatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_worker_topic_env_mismatches_total",
+		Help: "The execution environment variables disagreeing with the topic on chain",
+	}, []string{"topic", "variable"})
+
+	topicEnvFallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_worker_topic_env_fallbacks_total",
+		Help: "The executions using their environment because their topic could not be fetched from chain",
+	}, []string{"topic"})
+
+	leaderBundles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_leader_bundles_total",
+		Help: "The bundles received by the leader, by whether they were accepted into the payload or why they were rejected",
+	}, []string{"topic", "mode", "outcome", "reason"})
+
+	broadcastAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_chain_broadcast_attempts_total",
+		Help: "The transactions broadcast to the Allora blockchain, including retries, by whether they were confirmed",
+	}, []string{"topic", "msg_type", "outcome"})
+
+	txConfirmationLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "allora_chain_tx_confirmation_seconds",
+		Help:    "The time from a transaction being accepted by an Allora blockchain node to it being included in a block",
+		Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"topic", "msg_type"})
+
+	txGasUsed = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "allora_chain_tx_gas_used",
+		Help:    "The gas used by transactions included in the Allora blockchain",
+		Buckets: prometheus.ExponentialBuckets(50000, 2, 10),
+	}, []string{"topic", "msg_type"})
+
+	walletBalance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "allora_chain_wallet_balance",
+		Help: "The balance of the node account on the Allora blockchain",
+	}, []string{"address", "denom"})
+
+	chainConnectionState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "allora_chain_connection_state",
+		Help: "The state of the connection to the Allora blockchain, 1 for the current state",
+	}, []string{"state"})
+
+	rpcEndpointUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "allora_chain_rpc_endpoint_up",
+		Help: "Whether the Allora blockchain RPC endpoint is healthy",
+	}, []string{"endpoint"})
+
+	rpcEndpointLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "allora_chain_rpc_endpoint_latency_seconds",
+		Help: "Moving average of the Allora blockchain RPC endpoint latency",
+	}, []string{"endpoint"})
+
+	feesSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_chain_fees_spent_total",
+		Help: "The fees paid for transactions accepted by the Allora blockchain",
+	}, []string{"topic", "msg_type", "denom"})
+
+	bulkBundles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_chain_bulk_bundles_total",
+		Help: "The bundles of leader bulk payloads, by whether they were submitted to the chain, failed or were dropped to fit in the transaction",
+	}, []string{"topic", "msg_type", "outcome"})
+
+	admissionRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "allora_head_rejected_requests_total",
+		Help: "The execution requests rejected by the head, by the rate or in-flight limit they hit",
+	}, []string{"reason"})
+
+	executionsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "allora_head_executions_in_flight",
+		Help: "The executions running on the head, when in-flight executions are limited",
+	})
+
+	executionsQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "allora_head_executions_queued",
+		Help: "The executions waiting for an in-flight slot on the head",
 	})
 )
 
@@ -82,6 +180,24 @@ func init() {
 	prometheus.MustRegister(reputerResponse)
 	prometheus.MustRegister(workerChainCommit)
 	prometheus.MustRegister(reputerChainCommit)
+	prometheus.MustRegister(executionDuration)
+	prometheus.MustRegister(wasmExitCodes)
+	prometheus.MustRegister(leaderBundles)
+	prometheus.MustRegister(valuesDropped)
+	prometheus.MustRegister(topicEnvMismatches)
+	prometheus.MustRegister(topicEnvFallbacks)
+	prometheus.MustRegister(broadcastAttempts)
+	prometheus.MustRegister(txConfirmationLatency)
+	prometheus.MustRegister(txGasUsed)
+	prometheus.MustRegister(walletBalance)
+	prometheus.MustRegister(chainConnectionState)
+	prometheus.MustRegister(rpcEndpointUp)
+	prometheus.MustRegister(rpcEndpointLatency)
+	prometheus.MustRegister(feesSpent)
+	prometheus.MustRegister(bulkBundles)
+	prometheus.MustRegister(admissionRejected)
+	prometheus.MustRegister(executionsInFlight)
+	prometheus.MustRegister(executionsQueued)
 }
 
 func main() {
@@ -94,108 +210,186 @@ func connectToAlloraBlockchain(cfg AppChainConfig, log zerolog.Logger) (*AppChai
 		log.Warn().Err(err).Msg("error connecting to allora blockchain")
 		return nil, err
 	} else {
-		log.Info().Msg("connected to allora blockchain")
+		log.Info().Bool("submitTx", appchain.Config.SubmitTx).Msg("connected to allora blockchain")
 	}
-	appchain.Config.SubmitTx = true
 	return appchain, nil
 }
 
-func NewAlloraExecutor(e blockless.Executor) *AlloraExecutor {
+func NewAlloraExecutor(e blockless.Executor, chain *ChainConnection, log zerolog.Logger) *AlloraExecutor {
 	return &AlloraExecutor{
 		Executor: e,
+		chain:    chain,
+		log:      log,
 	}
 }
 
-func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request) (execute.Result, error) {
+// signPayload signs the payload of a worker response with the node account.
+func signPayload(ctx context.Context, appChain *AppChain, accountName string, payload []byte) ([]byte, cryptotypes.PubKey, error) {
+	_, span := tracer.Start(ctx, "worker.sign")
+	sig, pk, err := appChain.Client.Context().Keyring.Sign(accountName, payload, signing.SignMode_SIGN_MODE_DIRECT)
+	endSpan(span, err)
+	return sig, pk, err
+}
+
+func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request) (result execute.Result, err error) {
+	log := e.log.With().Str("request", requestID).Str("function", req.FunctionID).Logger()
+
+	// Continue the trace of the head, and keep it for the leader to submit the results in.
+	parentCtx := extractTraceContext(context.Background(), req.Config.Environment)
+	ctx, span := tracer.Start(parentCtx, "worker.execute", trace.WithAttributes(attrRequestID.String(requestID), attribute.String("allora.function", req.FunctionID)))
+	defer func() { endSpan(span, err) }()
+	if parent := trace.SpanContextFromContext(parentCtx); parent.IsValid() {
+		executedRequests.add(requestID, parent)
+	} else {
+		executedRequests.add(requestID, span.SpanContext())
+	}
+
 	// First call the blockless.Executor's method to get the result
-	result, err := e.Executor.ExecuteFunction(requestID, req)
-	// print incoming result:
-	fmt.Println("Result from WASM: ", result.Result.Stdout)
+	start := time.Now()
+	_, wasmSpan := tracer.Start(ctx, "wasm.execute")
+	result, err = e.Executor.ExecuteFunction(requestID, req)
+	duration := time.Since(start)
+	exitCode := strconv.Itoa(result.Result.ExitCode)
+	wasmSpan.SetAttributes(attribute.Int("allora.exit_code", result.Result.ExitCode))
+	endSpan(wasmSpan, err)
+	log.Debug().Str("stdout", result.Result.Stdout).Int("exit_code", result.Result.ExitCode).Dur("duration", duration).Msg("result from WASM")
+
+	// Labels of the execution metrics, filled in as they become known.
+	topicLabel, mode, outcome := "", "", "unsigned"
+	defer func() {
+		if err != nil {
+			outcome = "error"
+		}
+		opsProcessed.WithLabelValues(topicLabel, mode, outcome).Inc()
+		executionDuration.WithLabelValues(blockless.WorkerNode.String(), topicLabel, mode, outcome).Observe(duration.Seconds())
+		wasmExitCodes.WithLabelValues(topicLabel, mode, exitCode).Inc()
+	}()
 
 	// Get the topicId from the env var
 	var topicId uint64
 	var topicFound bool = false
 	var alloraBlockHeightCurrent int64 = notFoundValue
 	var alloraBlockHeightEval int64 = notFoundValue
-	var topicAllowsNegative bool = false
+	var topicAllowsNegative bool = false // as given in the environment, unless the topic is found on chain
 	for _, envVar := range req.Config.Environment {
 		if envVar.Name == "TOPIC_ID" {
 			topicFound = true
-			// Get the topicId from the environment variable from str  as uint64
-			topicId, err = strconv.ParseUint(envVar.Value, 10, 64)
+			// The topic is named as in the head request, e.g. "1" or "1/reputer".
+			var topic TopicRef
+			topic, err = ParseRequestTopic(envVar.Value)
 			if err != nil {
-				// check if it ends with "/reputer" and extract the previous numerical value
-				if len(envVar.Value) > 8 && envVar.Value[len(envVar.Value)-8:] == "/reputer" {
-					topicId, err = strconv.ParseUint(envVar.Value[:len(envVar.Value)-8], 10, 64)
-					if err != nil {
-						fmt.Println("Error parsing topic ID: ", err)
-						return result, err
-					}
-				} else {
-					fmt.Println("Error parsing topic ID: no int, no '/reputer' suffix ", err)
-					return result, err
-				}
+				log.Error().Err(err).Str("topic", envVar.Value).Msg("could not parse topic ID")
+				return result, err
 			}
-			fmt.Println("TOPIC_ID: ", topicId)
+			topicId = topic.ID
+			log = log.With().Uint64("topic", topicId).Logger()
+			topicLabel = strconv.FormatUint(topicId, 10)
+			span.SetAttributes(attrTopic.Int64(int64(topicId)))
 		} else if envVar.Name == "ALLORA_BLOCK_HEIGHT_CURRENT" {
 			alloraBlockHeightCurrent, err = strconv.ParseInt(envVar.Value, 10, 64)
 			if err != nil {
-				fmt.Println("Error parsing ALLORA_BLOCK_HEIGHT_CURRENT: ", err)
+				log.Error().Err(err).Str("value", envVar.Value).Msg("could not parse ALLORA_BLOCK_HEIGHT_CURRENT")
 				return result, err
 			}
-			fmt.Println("ALLORA_BLOCK_HEIGHT_CURRENT: ", alloraBlockHeightCurrent)
+			log = log.With().Int64("block_height", alloraBlockHeightCurrent).Logger()
+			span.SetAttributes(attrNonce.Int64(alloraBlockHeightCurrent))
 		} else if envVar.Name == "ALLORA_BLOCK_HEIGHT_EVAL" {
 			// Get the topicId from the environment variable from str  as uint64
 			alloraBlockHeightEval, err = strconv.ParseInt(envVar.Value, 10, 64)
 			if err != nil {
-				fmt.Println("Error parsing ALLORA_BLOCK_HEIGHT_EVAL: ", err)
+				log.Error().Err(err).Str("value", envVar.Value).Msg("could not parse ALLORA_BLOCK_HEIGHT_EVAL")
 				return result, err
 			}
-			fmt.Println("ALLORA_BLOCK_HEIGHT_EVAL: ", alloraBlockHeightEval)
-		} else if envVar.Name == "LOSS_FUNCTION_ALLOWS_NEGATIVE" {
+			log = log.With().Int64("block_height_eval", alloraBlockHeightEval).Logger()
+		} else if envVar.Name == envLossFunctionAllowsNegative {
 			if envVar.Value == "true" {
 				topicAllowsNegative = true
 			}
-			fmt.Println("LOSS_FUNCTION_ALLOWS_NEGATIVE: ", strconv.FormatBool(topicAllowsNegative))
+			log.Debug().Bool("allows_negative", topicAllowsNegative).Msg("LOSS_FUNCTION_ALLOWS_NEGATIVE found")
 		}
 	}
 	if !topicFound {
-		fmt.Println("No topic ID found in the environment variables.")
+		log.Info().Msg("no TOPIC_ID found in the environment variables, returning result as is")
 		return result, nil
 	}
 	if alloraBlockHeightCurrent == notFoundValue {
-		fmt.Println("No ALLORA_BLOCK_HEIGHT_CURRENT found in the environment variables.")
+		log.Info().Msg("no ALLORA_BLOCK_HEIGHT_CURRENT found in the environment variables, returning result as is")
 		return result, nil
 	}
 
-	if e.appChain == nil {
-		fmt.Println("Appchain is nil, cannot sign the payload, returning as is.")
+	// Use the same chain client for the whole execution, even if the connection is swapped meanwhile.
+	appChain := e.chain.AppChain()
+	if appChain == nil || !appChain.Config.SubmitTx {
+		log.Warn().Msg("appchain is nil or cannot sign, cannot sign the payload, returning as is")
 		return result, nil
 	}
+	log = log.With().Str("mode", appChain.Config.WorkerMode).Logger()
+	mode = appChain.Config.WorkerMode
+	span.SetAttributes(attrMode.String(mode))
+
+	// The topic on chain is the source of truth, the environment of the execution is only used if it
+	// cannot be fetched.
+	topic, err := appChain.Config.TopicCache.Get(ctx, appChain.EmissionsQueryClient, topicId)
+	if err != nil {
+		if rejectErr := topicEnvFallback(appChain.Config.TopicEnvMismatch, topicId, topicLabel, err); rejectErr != nil {
+			log.Error().Err(rejectErr).Msg("rejecting execution")
+			return result, rejectErr
+		}
+		log.Warn().Err(err).Msg("could not get topic from chain, using the execution environment")
+	} else {
+		if mismatches := checkTopicEnv(topic, req.Config.Environment); len(mismatches) > 0 {
+			names := make([]string, len(mismatches))
+			for i, mismatch := range mismatches {
+				topicEnvMismatches.WithLabelValues(topicLabel, mismatch.Name).Inc()
+				names[i] = mismatch.String()
+			}
+			if appChain.Config.TopicEnvMismatch == TopicEnvMismatchReject {
+				err = fmt.Errorf("execution environment disagrees with topic %d on chain: %s", topicId, strings.Join(names, ", "))
+				log.Error().Err(err).Msg("rejecting execution")
+				return result, err
+			}
+			log.Warn().Strs("mismatches", names).Msg("execution environment disagrees with topic on chain, using the chain values")
+		}
+		topicAllowsNegative = topic.AllowNegative
+		log = log.With().Str("loss_method", topic.LossMethod).Bool("allows_negative", topicAllowsNegative).Logger()
+	}
+	// Loss values are transformed by the pipeline of the topic, which its metadata on chain may set.
+	pipeline, err := appChain.Config.ValuePipelines.For(topicId, topic, topicAllowsNegative)
+	if err != nil {
+		log.Error().Err(err).Msg("could not get value pipeline of topic")
+		return result, err
+	}
+	log = log.With().Str("value_transform", pipeline.Spec).Logger()
 	// Iterate env vars to get the ALLORA_NONCE, if found, sign it and add the signature to the result
 	// Check if this worker node is reputer or worker mode
-	if e.appChain.Config.WorkerMode == WorkerModeWorker {
+	if appChain.Config.WorkerMode == WorkerModeWorker {
 		// Get the nonce from the environment variable, convert to bytes
 		// If appchain is null or SubmitTx is false, do not sign the nonce
-		if e.appChain != nil && e.appChain.Client != nil {
+		if appChain != nil && appChain.Client != nil {
 			// Get the account from the appchain
-			accountName := e.appChain.Account.Name
+			accountName := appChain.Account.Name
+			if result.Result.ExitCode != 0 {
+				log.Warn().Int("exit_code", result.Result.ExitCode).Msg("function failed, returning result as is")
+				return result, nil
+			}
 			var responseValue InferenceForecastResponse
-			err = json.Unmarshal([]byte(result.Result.Stdout), &responseValue)
+			responseValue, err = parseInferenceForecastResponse(result.Result.Stdout, appChain.Config.AddressPrefix)
 			if err != nil {
-				fmt.Println("Error serializing InferenceForecastResponse proto message: ", err)
+				err = fmt.Errorf("invalid worker output: %w", err)
+				log.Error().Err(err).Msg("could not parse InferenceForecastResponse from stdout")
+				return invalidOutput(result, err), err
 			} else {
 				// Define an empty bundle
 				inferenceForecastsBundle := &types.InferenceForecastBundle{}
 				// Build inference if existent
 				if responseValue.InfererValue != "" {
-					infererValue, err := alloraMath.NewDecFromString(responseValue.InfererValue)
+					infererValue, err := alloraMath.NewDecFromString(string(responseValue.InfererValue))
 					if err != nil {
 						return result, err
 					}
 					inference := &types.Inference{
 						TopicId:     topicId,
-						Inferer:     e.appChain.Address,
+						Inferer:     appChain.Address,
 						Value:       infererValue,
 						BlockHeight: alloraBlockHeightCurrent,
 					}
@@ -203,22 +397,16 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 				}
 				// Build Forecast
 				if len(responseValue.ForecasterValues) > 0 {
+					values, err := transformValues(pipeline, responseValue.ForecasterValues, topicLabel, mode, log)
+					if err != nil {
+						log.Error().Err(err).Msg("could not transform forecaster values")
+						return result, err
+					}
 					var forecasterElements []*types.ForecastElement
-					for _, val := range responseValue.ForecasterValues {
-						decVal, err := alloraMath.NewDecFromString(val.Value)
-						if err != nil {
-							return result, err
-						}
-						if !topicAllowsNegative {
-							decVal, err = alloraMath.Log10(decVal)
-							if err != nil {
-								fmt.Println("Error Log10 forecasterElements: ", err)
-								return result, err
-							}
-						}
+					for _, val := range values {
 						forecasterElements = append(forecasterElements, &types.ForecastElement{
 							Inferer: val.Worker,
-							Value:   decVal,
+							Value:   val.Value,
 						})
 					}
 
@@ -226,7 +414,7 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 						forecasterValues := &types.Forecast{
 							TopicId:          topicId,
 							BlockHeight:      alloraBlockHeightCurrent,
-							Forecaster:       e.appChain.Address,
+							Forecaster:       appChain.Address,
 							ForecastElements: forecasterElements,
 						}
 						inferenceForecastsBundle.Forecast = forecasterValues
@@ -237,18 +425,18 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 				protoBytesIn := make([]byte, 0) // Create a byte slice with initial length 0 and capacity greater than 0
 				protoBytesIn, err := inferenceForecastsBundle.XXX_Marshal(protoBytesIn, true)
 				if err != nil {
-					fmt.Println("Error Marshalling InferenceForecastsBundle: ", err)
+					log.Error().Err(err).Msg("could not marshal InferenceForecastsBundle")
 					return result, err
 				}
-				sig, pk, err := e.appChain.Client.Context().Keyring.Sign(accountName, protoBytesIn, signing.SignMode_SIGN_MODE_DIRECT)
+				sig, pk, err := signPayload(ctx, appChain, accountName, protoBytesIn)
 				pkStr := hex.EncodeToString(pk.Bytes())
 				if err != nil {
-					fmt.Println("Error signing the InferenceForecastsBundle message: ", err)
+					log.Error().Err(err).Msg("could not sign InferenceForecastsBundle")
 					return result, err
 				}
 				// Create workerDataBundle with signature
 				workerDataBundle := &types.WorkerDataBundle{
-					Worker:                             e.appChain.Address,
+					Worker:                             appChain.Address,
 					InferenceForecastsBundle:           inferenceForecastsBundle,
 					InferencesForecastsBundleSignature: sig,
 					Pubkey:                             pkStr,
@@ -263,28 +451,29 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 				// Serialize the workerDataBundle into json
 				workerDataBundleBytes, err := json.Marshal(workerDataResponse)
 				if err != nil {
-					fmt.Println("Error serializing WorkerDataBundle: ", err)
+					log.Error().Err(err).Msg("could not serialize WorkerDataBundle")
 					return result, err
 				}
 
 				// increament the number of responses made by worker
-				workerResponse.Inc()
+				workerResponse.WithLabelValues(topicLabel).Inc()
+				outcome = "signed"
 
-				outputJson := string(workerDataBundleBytes)
-				fmt.Println("Signed OutputJson sent to consensus: ", outputJson)
-				result.Result.Stdout = outputJson
+				log.Info().Msg("signed worker payload, sending to consensus")
+				log.Debug().RawJSON("payload", workerDataBundleBytes).Msg("signed worker payload")
+				result.Result.Stdout = string(workerDataBundleBytes)
 			}
 		} else {
-			fmt.Println("Appchain is nil, cannot sign the payload.")
+			log.Warn().Msg("appchain client is nil, cannot sign the payload")
 		}
-	} else if e.appChain.Config.WorkerMode == WorkerModeReputer {
+	} else if appChain.Config.WorkerMode == WorkerModeReputer {
 		// Get the nonce from the environment variable, convert to bytes
 		// If appchain is null or SubmitTx is false, do not sign the nonce
-		if e.appChain != nil && e.appChain.Client != nil {
-			fmt.Println("Worker mode is Reputer, packaging output for consensus.")
+		if appChain != nil && appChain.Client != nil {
+			log.Debug().Msg("packaging reputer output for consensus")
 			// Check also the EVAL nonce
 			if alloraBlockHeightEval == notFoundValue {
-				fmt.Println("No ALLORA_BLOCK_HEIGHT_EVAL found in the environment variables.")
+				log.Info().Msg("no ALLORA_BLOCK_HEIGHT_EVAL found in the environment variables, returning result as is")
 				return result, nil
 			}
 			// Create ReputerRequestNonce
@@ -294,36 +483,24 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 				},
 			}
 
-			// Now get the string of the value, unescape it and unmarshall into ValueBundle
-			// Unmarshal the "value" field from the LossResponse struct
-			var wasmValueBundle ReputerWASMResponse
-			err = json.Unmarshal([]byte(result.Result.Stdout), &wasmValueBundle)
+			// The ValueBundle comes in an envelope, or JSON encoded in the value of a ReputerWASMResponse
+			var nestedValueBundle ValueBundle
+			nestedValueBundle, err = parseValueBundle(result.Result.Stdout, appChain.Config.AddressPrefix)
 			if err != nil {
-				e.appChain.Logger.Error().Err(err).Msg("Error unmarshalling JSON Value.")
+				log.Error().Err(err).Msg("could not parse ValueBundle from stdout")
 				return result, err
 			}
-			var nestedValueBundle ValueBundle
-			err = json.Unmarshal([]byte(wasmValueBundle.Value), &nestedValueBundle)
+
+			// The combined and naive values are always needed, whatever the error policy.
+			combinedValue, err := transformValue(pipeline, nestedValueBundle.CombinedValue)
 			if err != nil {
-				e.appChain.Logger.Error().Err(err).Msg("Error unmarshalling nested JSON ValueBundle:")
+				log.Error().Err(err).Msg("could not transform combined value")
 				return result, err
 			}
-
-			combinedValue := alloraMath.MustNewDecFromString(nestedValueBundle.CombinedValue)
-			naiveValue := alloraMath.MustNewDecFromString(nestedValueBundle.NaiveValue)
-
-			// Log10 values the output when never_negative is set as true
-			if !topicAllowsNegative {
-				combinedValue, err = alloraMath.Log10(combinedValue)
-				if err != nil {
-					e.appChain.Logger.Error().Err(err).Msg("Error Log10 for Combined Value:")
-					return result, err
-				}
-				naiveValue, err = alloraMath.Log10(naiveValue)
-				if err != nil {
-					e.appChain.Logger.Error().Err(err).Msg("Error Log10 for Naive Value:")
-					return result, err
-				}
+			naiveValue, err := transformValue(pipeline, nestedValueBundle.NaiveValue)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform naive value")
+				return result, err
 			}
 
 			// Get the values from the nestedValueBundle
@@ -335,96 +512,66 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 				inInferVal     []*types.WorkerAttributedValue
 			)
 
-			for _, inf := range nestedValueBundle.InfererValues {
-				value, err := alloraMath.NewDecFromString(inf.Value)
-				if err != nil {
-					return result, err
-				}
-				if !topicAllowsNegative {
-					value, err = alloraMath.Log10(value)
-					if err != nil {
-						e.appChain.Logger.Error().Err(err).Msg("Error Log10 for Inferer Value:")
-						return result, err
-					}
-				}
+			values, err := transformValues(pipeline, nestedValueBundle.InfererValues, topicLabel, mode, log)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform inferer values")
+				return result, err
+			}
+			for _, val := range values {
 				inferVal = append(inferVal, &types.WorkerAttributedValue{
-					Worker: inf.Worker,
-					Value:  value,
+					Worker: val.Worker,
+					Value:  val.Value,
 				})
 			}
-			for _, inf := range nestedValueBundle.ForecasterValues {
-				value, err := alloraMath.NewDecFromString(inf.Value)
-				if err != nil {
-					return result, err
-				}
-				if !topicAllowsNegative {
-					value, err = alloraMath.Log10(value)
-					if err != nil {
-						e.appChain.Logger.Error().Err(err).Msg("Error Log10 for Forecaster Value:")
-						return result, err
-					}
-				}
+			values, err = transformValues(pipeline, nestedValueBundle.ForecasterValues, topicLabel, mode, log)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform forecaster values")
+				return result, err
+			}
+			for _, val := range values {
 				forecastsVal = append(forecastsVal, &types.WorkerAttributedValue{
-					Worker: inf.Worker,
-					Value:  value,
+					Worker: val.Worker,
+					Value:  val.Value,
 				})
 			}
-			for _, inf := range nestedValueBundle.OneOutInfererValues {
-				value, err := alloraMath.NewDecFromString(inf.Value)
-				if err != nil {
-					return result, err
-				}
-				if !topicAllowsNegative {
-					value, err = alloraMath.Log10(value)
-					if err != nil {
-						e.appChain.Logger.Error().Err(err).Msg("Error Log10 for OutInferer Value:")
-						return result, err
-					}
-				}
+			values, err = transformValues(pipeline, nestedValueBundle.OneOutInfererValues, topicLabel, mode, log)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform one-out inferer values")
+				return result, err
+			}
+			for _, val := range values {
 				outInferVal = append(outInferVal, &types.WithheldWorkerAttributedValue{
-					Worker: inf.Worker,
-					Value:  value,
+					Worker: val.Worker,
+					Value:  val.Value,
 				})
 			}
-			for _, inf := range nestedValueBundle.OneOutForecasterValues {
-				value, err := alloraMath.NewDecFromString(inf.Value)
-				if err != nil {
-					return result, err
-				}
-				if !topicAllowsNegative {
-					value, err = alloraMath.Log10(value)
-					if err != nil {
-						e.appChain.Logger.Error().Err(err).Msg("Error Log10 for OutForecaster Value:")
-						return result, err
-					}
-				}
+			values, err = transformValues(pipeline, nestedValueBundle.OneOutForecasterValues, topicLabel, mode, log)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform one-out forecaster values")
+				return result, err
+			}
+			for _, val := range values {
 				outForecastVal = append(outForecastVal, &types.WithheldWorkerAttributedValue{
-					Worker: inf.Worker,
-					Value:  value,
+					Worker: val.Worker,
+					Value:  val.Value,
 				})
 			}
-			for _, inf := range nestedValueBundle.OneInForecasterValues {
-				value, err := alloraMath.NewDecFromString(inf.Value)
-				if err != nil {
-					return result, err
-				}
-				if !topicAllowsNegative {
-					value, err = alloraMath.Log10(value)
-					if err != nil {
-						e.appChain.Logger.Error().Err(err).Msg("Error Log10 for InForecaster Value:")
-						return result, err
-					}
-				}
+			values, err = transformValues(pipeline, nestedValueBundle.OneInForecasterValues, topicLabel, mode, log)
+			if err != nil {
+				log.Error().Err(err).Msg("could not transform one-in forecaster values")
+				return result, err
+			}
+			for _, val := range values {
 				inInferVal = append(inInferVal, &types.WorkerAttributedValue{
-					Worker: inf.Worker,
-					Value:  value,
+					Worker: val.Worker,
+					Value:  val.Value,
 				})
 			}
 
 			newValueBundle := &types.ValueBundle{
 				TopicId:                topicId,
 				ReputerRequestNonce:    reputerRequestNonce,
-				Reputer:                e.appChain.Address,
+				Reputer:                appChain.Address,
 				CombinedValue:          combinedValue,
 				NaiveValue:             naiveValue,
 				InfererValues:          inferVal,
@@ -436,17 +583,17 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 
 			// Marshall and sign the bundle
 			// Get the account from the appchain
-			accountName := e.appChain.Account.Name
+			accountName := appChain.Account.Name
 			protoBytesIn := make([]byte, 0)
-			protoBytesIn, err := newValueBundle.XXX_Marshal(protoBytesIn, true)
+			protoBytesIn, err = newValueBundle.XXX_Marshal(protoBytesIn, true)
 			if err != nil {
-				fmt.Println("Error Marshalling newValueBundle: ", err)
+				log.Error().Err(err).Msg("could not marshal ValueBundle")
 				return result, err
 			}
-			sig, pk, err := e.appChain.Client.Context().Keyring.Sign(accountName, protoBytesIn, signing.SignMode_SIGN_MODE_DIRECT)
+			sig, pk, err := signPayload(ctx, appChain, accountName, protoBytesIn)
 			pkStr := hex.EncodeToString(pk.Bytes())
 			if err != nil {
-				fmt.Println("Error signing the InferenceForecastsBundle message: ", err)
+				log.Error().Err(err).Msg("could not sign ValueBundle")
 				return result, err
 			}
 
@@ -467,21 +614,20 @@ func (e *AlloraExecutor) ExecuteFunction(requestID string, req execute.Request)
 			// Serialize the workerDataBundle into json
 			reputerDataResponseBytes, err := json.Marshal(reputerDataResponse)
 			if err != nil {
-				fmt.Println("Error serializing WorkerDataBundle: ", err)
+				log.Error().Err(err).Msg("could not serialize ReputerDataResponse")
 				return result, err
 			}
 
 			// increament the number of responses made by reputer
-			workerResponse.Inc()
+			reputerResponse.WithLabelValues(topicLabel).Inc()
+			outcome = "signed"
 
-			outputJson := string(reputerDataResponseBytes)
-			fmt.Println("Signed OutputJson sent to consensus: ", outputJson)
-			result.Result.Stdout = outputJson
+			log.Info().Msg("signed reputer payload, sending to consensus")
+			log.Debug().RawJSON("payload", reputerDataResponseBytes).Msg("signed reputer payload")
+			result.Result.Stdout = string(reputerDataResponseBytes)
 		}
 	}
 
-	// increament the number of operations processed by worker or reputer
-	opsProcessed.Inc()
 	return result, err
 }
 
@@ -496,14 +642,68 @@ func run() int {
 
 	// Parse CLI flags and validate that the configuration is valid.
 	cfg := parseFlags()
+	var err error
+
+	// Load the account secrets and keep them out of the logs.
+	redactor := &Redactor{}
+	cfg.AppChainConfig.AddressRestoreMnemonic, err = loadSecret(cfg.RestoreMnemonic, cfg.RestoreMnemonicFile, envRestoreMnemonic)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load the allora chain restore mnemonic")
+		return failure
+	}
+	cfg.AppChainConfig.AddressAccountPassphrase, err = loadSecret(cfg.AccountPassphrase, cfg.AccountPassphraseFile, envAccountPassphrase)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load the allora chain account password")
+		return failure
+	}
+	cfg.RestoreMnemonic, cfg.AccountPassphrase = "", ""
+	cfg.MetricsConfig.BasicAuthPassword, err = loadSecret("", cfg.MetricsConfig.BasicAuthPasswordFile, envMetricsPassword)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load the metrics server password")
+		return failure
+	}
+	cfg.MetricsConfig.BearerToken, err = loadSecret("", cfg.MetricsConfig.BearerTokenFile, envMetricsBearerToken)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load the metrics server bearer token")
+		return failure
+	}
+	redactor.Add(cfg.AppChainConfig.AddressRestoreMnemonic, cfg.AppChainConfig.AddressAccountPassphrase,
+		cfg.MetricsConfig.BasicAuthPassword, cfg.MetricsConfig.BearerToken)
+	var apiAuth *APIAuth
+	if cfg.APIAuth.ClientsFile != "" {
+		apiAuth, err = LoadAPIAuth(cfg.APIAuth.ClientsFile)
+		if err != nil {
+			log.Error().Err(err).Str("file", cfg.APIAuth.ClientsFile).Msg("could not load the REST API auth config")
+			return failure
+		}
+		redactor.Add(apiAuth.Secrets()...)
+	}
+	addMetricTopics(cfg.MetricsConfig.Topics...)
+	addMetricTopics(cfg.AppChainConfig.TopicIds...)
+	addMetricTopics(apiAuth.Topics()...)
 
-	// Set log level.
+	// Set log format and level.
+	rootLog, err := newLogger(cfg.LogFormat, redactor.Writer(os.Stderr))
+	if err != nil {
+		log.Error().Err(err).Msg("could not create logger")
+		return failure
+	}
+	log = rootLog
 	level, err := zerolog.ParseLevel(cfg.Log.Level)
 	if err != nil {
 		log.Error().Err(err).Str("level", cfg.Log.Level).Msg("could not parse log level")
 		return failure
 	}
 	log = log.Level(level)
+	logLevels, err := parseLogLevels(cfg.LogLevels)
+	if err != nil {
+		log.Error().Err(err).Msg("could not parse subsystem log levels")
+		return failure
+	}
+	executorLog := subsystemLogger(log, LogSubsystemExecutor, logLevels)
+	appChainLog := subsystemLogger(log, LogSubsystemAppChain, logLevels)
+	apiLog := subsystemLogger(log, LogSubsystemAPI, logLevels)
+	p2pLog := subsystemLogger(log, LogSubsystemP2P, logLevels)
 
 	// Determine node role.
 	role, err := parseNodeRole(cfg.Role)
@@ -512,6 +712,20 @@ func run() int {
 		return failure
 	}
 
+	// Set up tracing, flushing the remaining spans on exit.
+	shutdownTracing, err := setupTracing(context.Background(), cfg.TracingConfig, role.String())
+	if err != nil {
+		log.Error().Err(err).Msg("could not set up tracing")
+		return failure
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Warn().Err(err).Msg("could not flush traces")
+		}
+	}()
+
 	// Convert workspace path to an absolute one.
 	workspace, err := filepath.Abs(cfg.Workspace)
 	if err != nil {
@@ -519,6 +733,9 @@ func run() int {
 		return failure
 	}
 	cfg.Workspace = workspace
+	if cfg.HistoryConfig.DatabasePath == "" {
+		cfg.HistoryConfig.DatabasePath = filepath.Join(cfg.Workspace, defaultHistoryDB)
+	}
 
 	// Open the pebble peer database.
 	pdb, err := pebble.Open(cfg.PeerDatabasePath, &pebble.Options{Logger: &pebbleNoopLogger{}})
@@ -547,7 +764,7 @@ func run() int {
 	}
 
 	// Create libp2p host.
-	host, err := host.New(log, cfg.Host.Address, cfg.Host.Port,
+	host, err := host.New(p2pLog, cfg.Host.Address, cfg.Host.Port,
 		host.WithPrivateKey(cfg.Host.PrivateKey),
 		host.WithBootNodes(bootNodeAddrs),
 		host.WithDialBackPeers(peers),
@@ -606,7 +823,7 @@ func run() int {
 		}
 
 		// Create an executor.
-		executor, err := executor.New(log, execOptions...)
+		executor, err := executor.New(executorLog, execOptions...)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -616,7 +833,7 @@ func run() int {
 			return failure
 		}
 
-		alloraExecutor = NewAlloraExecutor(executor)
+		alloraExecutor = NewAlloraExecutor(executor, nil, executorLog)
 
 		opts = append(opts, node.WithExecutor(alloraExecutor))
 		opts = append(opts, node.WithWorkspace(cfg.Workspace))
@@ -635,45 +852,69 @@ func run() int {
 	// Create function store.
 	fstore := fstore.New(log, functionStore, cfg.Workspace)
 
-	// If we have topics specified, use those.
+	// If we have topics specified, use those. Workers only execute for the topics of their worker mode.
+	if role == blockless.WorkerNode {
+		topics, deprecated, err := WorkerTopics(cfg.Topics, cfg.AppChainConfig.WorkerMode)
+		if err != nil {
+			log.Error().Err(err).Str("mode", cfg.AppChainConfig.WorkerMode).Msg("invalid worker topic")
+			return failure
+		}
+		if len(deprecated) > 0 {
+			log.Warn().Strs("topics", deprecated).Strs("subscribed", topics).
+				Msg("topics given by their ID alone are deprecated, name them allora-topic-{id}-{mode}")
+		}
+		cfg.Topics = topics
+	}
 	if len(cfg.Topics) > 0 {
 		opts = append(opts, node.WithTopics(cfg.Topics))
 	}
 
-	var appchain *AppChain = nil
+	var chain *ChainConnection = nil
 	if role == blockless.WorkerNode {
 		cfg.AppChainConfig.NodeRole = role
 		cfg.AppChainConfig.AddressPrefix = "allo"
 		cfg.AppChainConfig.StringSeperator = "|"
 		cfg.AppChainConfig.LibP2PKey = host.ID().String()
 		cfg.AppChainConfig.MultiAddress = host.Addresses()[0]
-		appchain, err = connectToAlloraBlockchain(cfg.AppChainConfig, log)
+		cfg.AppChainConfig.GasStrategies, err = ParseGasStrategies(cfg.AppChainConfig.GasStrategySpecs, cfg.AppChainConfig.Gas, cfg.AppChainConfig.GasAdjustment)
+		if err != nil {
+			log.Error().Err(err).Msg("invalid gas configuration")
+			return failure
+		}
+		cfg.AppChainConfig.ValuePipelines, err = ParseValuePipelines(cfg.AppChainConfig.ValueTransformSpecs, cfg.AppChainConfig.ValueErrorPolicySpecs)
+		if err != nil {
+			log.Error().Err(err).Msg("invalid value transform configuration")
+			return failure
+		}
+		if cfg.AppChainConfig.GasPricesSpec != "" {
+			cfg.AppChainConfig.GasPrices, err = sdktypes.ParseDecCoins(cfg.AppChainConfig.GasPricesSpec)
+			if err != nil {
+				log.Error().Err(err).Str("gas_prices", cfg.AppChainConfig.GasPricesSpec).Msg("invalid gas prices")
+				return failure
+			}
+		}
+		cfg.AppChainConfig.FeeBudget, err = ParseFeeBudget(cfg.AppChainConfig.FeeBudgetLimit, cfg.AppChainConfig.FeeBudgetWindow)
+		if err == nil {
+			err = cfg.AppChainConfig.FeeBudget.CheckGasPrices(cfg.AppChainConfig.GasPrices)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("invalid fee budget")
+			return failure
+		}
+		switch cfg.AppChainConfig.TopicEnvMismatch {
+		case TopicEnvMismatchWarn, TopicEnvMismatchReject:
+		default:
+			log.Error().Str("policy", cfg.AppChainConfig.TopicEnvMismatch).Msg("invalid topic environment mismatch policy, expected warn or reject")
+			return failure
+		}
+		cfg.AppChainConfig.TopicCache = NewTopicCache(cfg.AppChainConfig.TopicCacheTTL, appChainLog)
+		cfg.AppChainConfig.DryRunSink = NewPayloadSink(cfg.AppChainConfig.DryRunOutput)
+		if cfg.AppChainConfig.DryRun {
+			log.Warn().Str("output", cfg.AppChainConfig.DryRunOutput).Msg("Dry run, transactions will not be broadcast to the Allora chain")
+		}
+		chain = NewChainConnection(cfg.AppChainConfig, appChainLog)
 		if alloraExecutor != nil {
-			alloraExecutor.appChain = appchain
-		}
-
-		if cfg.AppChainConfig.ReconnectSeconds > 0 {
-			go func(executor *AlloraExecutor) {
-				ticker := time.NewTicker(time.Second * time.Duration(math.Max(1, math.Min(float64(cfg.AppChainConfig.ReconnectSeconds), 3600))))
-				defer ticker.Stop()
-
-				for range ticker.C {
-					if appchain == nil || !appchain.Config.SubmitTx {
-						log.Debug().Uint64("reconnectSeconds", cfg.AppChainConfig.ReconnectSeconds).Msg("Attempt reconnection to allora blockchain")
-						appchain, err = connectToAlloraBlockchain(cfg.AppChainConfig, log)
-						if err != nil {
-							log.Debug().Msg("Failed to connect to allora blockchain")
-						} else {
-							log.Debug().Msg("Resetting up chain connection.")
-							if alloraExecutor != nil {
-								executor.appChain = appchain
-							} else {
-								log.Warn().Msg("No valid alloraExecutor with which to associate chain client.")
-							}
-						}
-					}
-				}
-			}(alloraExecutor) // Pass alloraExecutor as an argument to the goroutine
+			alloraExecutor.chain = chain
 		}
 	}
 
@@ -683,14 +924,14 @@ func run() int {
 		var data node.ChanData
 		msgerr := json.Unmarshal(msg, &data)
 		if msgerr == nil {
-			sendResultsToChain(log, appchain, data)
+			sendResultsToChain(appChainLog, chain.AppChain(), data)
 		} else {
 			log.Error().Err(msgerr).Msg("Unable to unmarshall")
 		}
 		resLoc.Unlock()
 	}
 	// Instantiate node.
-	node, err := node.New(log, host, peerstore, fstore, response, opts...)
+	node, err := node.New(p2pLog, host, peerstore, fstore, response, opts...)
 	if err != nil {
 		log.Error().Err(err).Msg("could not create node")
 		return failure
@@ -702,6 +943,11 @@ func run() int {
 	done := make(chan struct{})
 	failed := make(chan struct{})
 
+	// Connect to the Allora blockchain and keep the connection alive in the background.
+	if chain != nil {
+		go chain.Run(ctx)
+	}
+
 	// Start node main loop in a separate goroutine.
 	go func() {
 
@@ -720,16 +966,26 @@ func run() int {
 		log.Info().Msg("Allora Node stopped")
 	}()
 
-	// Start HTTP server for Prometheus metrics.
-	http.Handle("/metrics", promhttp.Handler())
-	go func() {
-		log.Info().Str("role", role.String()).Msg("Starting metrics server on :2112")
-		if err := http.ListenAndServe(":2112", nil); err != nil {
-			log.Error().Err(err).Msg("Could not start metric server")
+	// Start HTTP server for Prometheus metrics, unless disabled.
+	if cfg.MetricsConfig.Address != "" {
+		metricsServer, err := newMetricsServer(cfg.MetricsConfig, chain)
+		if err != nil {
+			log.Error().Err(err).Msg("could not create metrics server")
+			return failure
 		}
+		defer metricsServer.Close()
 
-		log.Info().Msg("Metrics server stopped")
-	}()
+		go func() {
+			log.Info().Str("role", role.String()).Str("address", cfg.MetricsConfig.Address).
+				Bool("tls", cfg.MetricsConfig.TLSCertFile != "").Bool("pprof", cfg.MetricsConfig.Pprof).Msg("Starting metrics server")
+			err := serveMetrics(metricsServer, cfg.MetricsConfig)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error().Err(err).Msg("Could not start metric server")
+			}
+
+			log.Info().Msg("Metrics server stopped")
+		}()
+	}
 
 	// If we're a head node - start the REST API.
 	if role == blockless.HeadNode {
@@ -738,30 +994,118 @@ func run() int {
 			log.Error().Err(err).Msg("REST API address is required")
 			return failure
 		}
+		if cfg.MaxRequestBody <= 0 {
+			log.Error().Int64("size", cfg.MaxRequestBody).Msg("REST API max body size must be positive")
+			return failure
+		}
 
 		// Create echo server and initialize logging.
 		server := echo.New()
 		server.HideBanner = true
 		server.HidePort = true
 
-		elog := lecho.From(log)
+		elog := lecho.From(apiLog)
 		server.Logger = elog
 		server.Use(lecho.Middleware(lecho.Config{Logger: elog}))
+		server.Use(limitRequestBody(cfg.MaxRequestBody))
+
+		// Open the execution history, looking up chain submissions if asked to.
+		var history *HistoryStore
+		if cfg.HistoryConfig.Size > 0 {
+			history, err = OpenHistoryStore(cfg.HistoryConfig.DatabasePath, cfg.HistoryConfig.Size, apiLog)
+			if err != nil {
+				log.Error().Err(err).Str("db", cfg.HistoryConfig.DatabasePath).Msg("could not open pebble history database")
+				return failure
+			}
+			defer history.Close()
+			if cfg.HistoryConfig.TrackSubmissions {
+				endpoints := NewEndpointPool(cfg.AppChainConfig.NodeRPCAddresses, func(address string) (*cosmosclient.Client, error) {
+					return getAlloraClient(cfg.AppChainConfig, address, appChainLog)
+				}, appChainLog)
+				history.submissions = NewSubmissionTracker(ctx, endpoints, cfg.HistoryConfig.SubmissionTimeout, appChainLog)
+			}
+		}
 
 		// Create an API handler.
-		api := api.New(log, node)
+		api := api.New(apiLog, node)
+		callbacks, err := NewCallbackPolicy(cfg.AsyncCallbackNets)
+		if err != nil {
+			log.Error().Err(err).Msg("invalid async callback networks")
+			return failure
+		}
+		jobs := NewAsyncJobs(cfg.AsyncJobRetention, callbacks, apiLog)
 
-		// Set endpoint handlers.
+		// Serve over HTTPS, verifying client certificates, if configured.
+		httpServer := &http.Server{Addr: cfg.API}
+		if cfg.APIAuth.TLSCertFile != "" || cfg.APIAuth.TLSKeyFile != "" {
+			httpServer.TLSConfig, err = apiTLSConfig(cfg.APIAuth.TLSCertFile, cfg.APIAuth.TLSKeyFile, cfg.APIAuth.ClientCAFile)
+			if err != nil {
+				log.Error().Err(err).Msg("could not configure REST API TLS")
+				return failure
+			}
+		} else if cfg.APIAuth.ClientCAFile != "" {
+			log.Error().Msg("REST API client CA requires --rest-api-tls-cert and --rest-api-tls-key")
+			return failure
+		}
+		if apiAuth == nil {
+			log.Warn().Msg("REST API authentication disabled, anyone reaching the API can execute and install functions")
+		}
+		admission, err := NewAdmission(cfg.Admission)
+		if err != nil {
+			log.Error().Err(err).Msg("could not configure REST API admission control")
+			return failure
+		}
+		server.IPExtractor, err = apiIPExtractor(cfg.Admission.TrustedProxies)
+		if err != nil {
+			log.Error().Err(err).Msg("could not configure REST API trusted proxies")
+			return failure
+		}
+		if cfg.Admission.ClientRate > 0 && cfg.BatchMaxSize > cfg.Admission.ClientBurst {
+			log.Warn().Int("batch_max_size", cfg.BatchMaxSize).Int("client_burst", cfg.Admission.ClientBurst).Msg("batches larger than the REST API client burst will be rejected")
+		}
+
+		// Set endpoint handlers, guarded by the permission they need. Request bodies are validated
+		// against the OpenAPI spec and executions are rate limited.
+		executeGuard := []echo.MiddlewareFunc{apiAuth.Require(PermissionExecute), validateRequest(), admission.RateLimit()}
+		installGuard := []echo.MiddlewareFunc{apiAuth.Require(PermissionInstall), validateRequest()}
+		readGuard := []echo.MiddlewareFunc{apiAuth.Require(PermissionRead), validateRequest()}
 		server.GET("/api/v1/health", api.Health)
-		server.POST("/api/v1/functions/execute", createExecutor(*api))
-		server.POST("/api/v1/functions/install", api.Install)
-		server.POST("/api/v1/functions/requests/result", api.ExecutionResult)
+		server.GET("/api/v1/openapi.json", serveOpenAPI)
+		server.POST("/api/v1/functions/execute", createExecutor(*api, history, admission), executeGuard...)
+		server.POST("/api/v1/functions/execute/async", createAsyncExecutor(*api, history, jobs, admission), executeGuard...)
+		server.GET("/api/v1/functions/execute/async/:id", getAsyncJob(jobs), readGuard...)
+		server.POST("/api/v1/functions/execute/stream", createStreamExecutor(*api, history, admission), executeGuard...)
+		server.POST("/api/v1/functions/execute/batch", createBatchExecutor(*api, history, admission, cfg.BatchConcurrency, cfg.BatchMaxSize), executeGuard...)
+		server.POST("/api/v1/functions/install", api.Install, installGuard...)
+		server.POST("/api/v1/functions/requests/result", api.ExecutionResult, readGuard...)
+		server.GET("/api/v1/executions", listExecutions(history), readGuard...)
+		server.GET("/api/v1/executions/:id", getExecution(history), readGuard...)
+
+		// Serve the gRPC API next to the REST API, if asked to.
+		if cfg.GRPCAPI != "" {
+			listener, err := net.Listen("tcp", cfg.GRPCAPI)
+			if err != nil {
+				log.Error().Err(err).Str("address", cfg.GRPCAPI).Msg("could not listen for the gRPC API")
+				return failure
+			}
+			grpcServer := newGRPCServer(*api, history, apiAuth, admission, httpServer.TLSConfig)
+			defer grpcServer.Stop()
+
+			go func() {
+				log.Info().Str("address", cfg.GRPCAPI).Bool("tls", httpServer.TLSConfig != nil).Msg("Node gRPC API starting")
+				err := grpcServer.Serve(listener)
+				if err != nil {
+					log.Warn().Err(err).Msg("Node gRPC API failed")
+				}
+				log.Info().Msg("Node gRPC API stopped")
+			}()
+		}
 
 		// Start API in a separate goroutine.
 		go func() {
 
-			log.Info().Str("port", cfg.API).Msg("Node API starting")
-			err := server.Start(cfg.API)
+			log.Info().Str("port", cfg.API).Bool("tls", httpServer.TLSConfig != nil).Bool("auth", apiAuth != nil).Msg("Node API starting")
+			err := server.StartServer(httpServer)
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
 				log.Warn().Err(err).Msg("Node API failed")
 				close(failed)
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const defaultMetricsAddress = ":2112"
+
+// Environment variables the metrics server credentials are read from when no file is given.
+const (
+	envMetricsPassword    = "ALLORA_METRICS_PASSWORD"
+	envMetricsBearerToken = "ALLORA_METRICS_BEARER_TOKEN"
+)
+
+// Label of the topics and worker modes the node does not know of. Clients name the topic of their
+// requests, labelling metrics with any of them would let clients create unlimited series.
+const metricLabelOther = "other"
+
+// metricTopics are the IDs of the topics the head labels its metrics with.
+var metricTopics = struct {
+	sync.RWMutex
+	ids map[string]bool
+}{ids: map[string]bool{}}
+
+// addMetricTopics adds topic IDs to those the head labels its metrics with.
+func addMetricTopics(ids ...string) {
+	metricTopics.Lock()
+	defer metricTopics.Unlock()
+	for _, id := range ids {
+		metricTopics.ids[id] = true
+	}
+}
+
+// topicMetricLabels returns the topic and mode labels of the metrics of an execution on the topic.
+func topicMetricLabels(topic TopicRef) (string, string) {
+	topicLabel, mode := topic.IDString(), topic.Mode
+	metricTopics.RLock()
+	known := metricTopics.ids[topicLabel]
+	metricTopics.RUnlock()
+	if !known {
+		topicLabel = metricLabelOther
+	}
+	if mode != WorkerModeWorker && mode != WorkerModeReputer {
+		mode = metricLabelOther
+	}
+	return topicLabel, mode
+}
+
+// newMetricsServer creates the metrics server on a mux of its own. If credentials are configured,
+// every request needs either the basic auth credentials or the bearer token.
+func newMetricsServer(cfg MetricsConfig, chain *ChainConnection) (*http.Server, error) {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("both a TLS certificate and key are needed to serve metrics over TLS")
+	}
+	if (cfg.BasicAuthUser == "") != cfg.BasicAuthPassword.Empty() {
+		return nil, errors.New("both a user and a password are needed for metrics basic auth")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/health", chainHealthHandler(chain))
+	if cfg.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return &http.Server{
+		Addr:    cfg.Address,
+		Handler: metricsAuth(cfg, mux),
+	}, nil
+}
+
+// serveMetrics serves the metrics server, over TLS if configured.
+func serveMetrics(server *http.Server, cfg MetricsConfig) error {
+	if cfg.TLSCertFile != "" {
+		return server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	return server.ListenAndServe()
+}
+
+// metricsAuth guards the handler with the configured credentials, if any.
+func metricsAuth(cfg MetricsConfig, next http.Handler) http.Handler {
+	if cfg.BasicAuthUser == "" && cfg.BearerToken.Empty() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.BearerToken.Empty() {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok && secureEqual(token, cfg.BearerToken.Reveal()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if cfg.BasicAuthUser != "" {
+			user, password, ok := r.BasicAuth()
+			// Evaluate both to not tell which one was wrong through timing.
+			userOk := secureEqual(user, cfg.BasicAuthUser)
+			passwordOk := secureEqual(password, cfg.BasicAuthPassword.Reveal())
+			if ok && userOk && passwordOk {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	cfg := MetricsConfig{
+		BasicAuthUser:     "prometheus",
+		BasicAuthPassword: NewSecret([]byte("password")),
+		BearerToken:       NewSecret([]byte("token")),
+	}
+	handler := metricsAuth(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	status := func(setup func(r *http.Request)) int {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		setup(r)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, status(func(r *http.Request) {}))
+	require.Equal(t, http.StatusOK, status(func(r *http.Request) { r.SetBasicAuth("prometheus", "password") }))
+	require.Equal(t, http.StatusUnauthorized, status(func(r *http.Request) { r.SetBasicAuth("prometheus", "wrong") }))
+	require.Equal(t, http.StatusOK, status(func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }))
+	require.Equal(t, http.StatusUnauthorized, status(func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") }))
+}
+
+func TestNewMetricsServer(t *testing.T) {
+	_, err := newMetricsServer(MetricsConfig{Address: defaultMetricsAddress, TLSCertFile: "cert.pem"}, nil)
+	require.Error(t, err)
+	_, err = newMetricsServer(MetricsConfig{Address: defaultMetricsAddress, BasicAuthUser: "prometheus"}, nil)
+	require.Error(t, err)
+
+	// pprof is only served when enabled.
+	for _, pprof := range []bool{false, true} {
+		server, err := newMetricsServer(MetricsConfig{Address: defaultMetricsAddress, Pprof: pprof}, nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil))
+		require.Equal(t, pprof, w.Code == http.StatusOK)
+	}
+}
+
+func TestTopicMetricLabels(t *testing.T) {
+	addMetricTopics("7")
+	topic, mode := topicMetricLabels(TopicRef{ID: 7, Mode: WorkerModeReputer})
+	require.Equal(t, "7", topic)
+	require.Equal(t, WorkerModeReputer, mode)
+
+	// Clients cannot make up labels.
+	topic, mode = topicMetricLabels(TopicRef{ID: 123456789, Mode: "custom"})
+	require.Equal(t, metricLabelOther, topic)
+	require.Equal(t, metricLabelOther, mode)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/labstack/echo/v4"
+)
+
+var echoPathParam = regexp.MustCompile(`:(\w+)`)
+
+// Default max size of the REST API request bodies, in bytes.
+const defaultMaxRequestBodySize = 4 << 20
+
+// limitRequestBody returns a middleware bounding the size of request bodies, so that they are
+// never read whole beyond the limit, even before authentication.
+func limitRequestBody(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if req.ContentLength > limit {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", limit))
+			}
+			req.Body = http.MaxBytesReader(ctx.Response(), req.Body, limit)
+			return next(ctx)
+		}
+	}
+}
+
+// readRequestBody reads the body of the request and puts it back for the next readers.
+func readRequestBody(ctx echo.Context) ([]byte, error) {
+	req := ctx.Request()
+	body, err := io.ReadAll(req.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit))
+	}
+	if err != nil {
+		return nil, badRequest(fmt.Sprintf("could not read request: %s", err))
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// validateRequest returns a middleware rejecting the requests whose body does not match the
+// OpenAPI spec of the route with 400.
+func validateRequest() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			body, err := readRequestBody(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Echo routes name their parameters :id, the spec {id}.
+			path := echoPathParam.ReplaceAllString(ctx.Path(), "{$1}")
+			err = headapi.ValidateRequest(req.Method, path, body)
+			var validationErr *headapi.ValidationError
+			if errors.As(err, &validationErr) {
+				return badRequest("invalid request: " + validationErr.Error())
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// badRequest returns a 400 error carrying the bad_request error code.
+func badRequest(message string) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, headapi.ErrorResponse{Code: headapi.ErrorBadRequest, Message: message})
+}
+
+// serveOpenAPI serves the OpenAPI spec of the head REST API.
+func serveOpenAPI(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationJSON, headapi.Spec())
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Latest version of the output envelope.
+const OutputEnvelopeVersion = 2
+
+// OutputEnvelope wraps the output of a function with its version and kind, the worker mode the
+// payload is for. Outputs without an envelope are in the legacy format of their mode, version 1.
+type OutputEnvelope struct {
+	Version int             `json:"version"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// unwrapOutput returns the payload of the output if it is an envelope, checking that it is of a
+// supported version and of the kind, or nil if it is not an envelope.
+func unwrapOutput(stdout string, kind string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(stdout), &fields) != nil || fields["version"] == nil {
+		return nil, nil
+	}
+	var envelope OutputEnvelope
+	if err := decodeStrict([]byte(stdout), &envelope); err != nil {
+		return nil, fmt.Errorf("output is not an envelope: %w", err)
+	}
+	if envelope.Version != OutputEnvelopeVersion {
+		return nil, fmt.Errorf("output envelope version %d is not supported, expected %d", envelope.Version, OutputEnvelopeVersion)
+	}
+	if envelope.Kind != kind {
+		return nil, fmt.Errorf("output envelope kind %q does not match the %s mode of the node", envelope.Kind, kind)
+	}
+	if len(envelope.Payload) == 0 || string(envelope.Payload) == "null" {
+		return nil, errors.New("output envelope has no payload")
+	}
+	return envelope.Payload, nil
+}
+
+// decodeStrict decodes a single JSON value, without unknown fields.
+func decodeStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("it is empty")
+		}
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("it has data after the value")
+	}
+	return nil
+}
+
+// parseInferenceForecastResponse parses the output of a function in worker mode, a JSON object with
+// an infererValue, a forecasterValue list of worker values, or both, possibly in an envelope.
+// Anything else in the output, values which are not finite numbers, forecasts of workers which are
+// not addresses with the prefix and forecasts of the same worker twice are rejected.
+func parseInferenceForecastResponse(stdout string, addressPrefix string) (InferenceForecastResponse, error) {
+	if strings.TrimSpace(stdout) == "" {
+		return InferenceForecastResponse{}, errors.New("output is empty")
+	}
+	payload, err := unwrapOutput(stdout, WorkerModeWorker)
+	if err != nil {
+		return InferenceForecastResponse{}, err
+	}
+	if payload == nil {
+		payload = []byte(stdout)
+	}
+	var response InferenceForecastResponse
+	if err := decodeStrict(payload, &response); err != nil {
+		return InferenceForecastResponse{}, fmt.Errorf("output is not an inference and forecast object: %w", err)
+	}
+
+	if response.InfererValue == "" && len(response.ForecasterValues) == 0 {
+		return InferenceForecastResponse{}, errors.New("output has neither infererValue nor forecasterValue")
+	}
+	if response.InfererValue != "" {
+		if _, err := parseOutputValue("infererValue", string(response.InfererValue)); err != nil {
+			return InferenceForecastResponse{}, err
+		}
+	}
+	workers := make(map[string]int, len(response.ForecasterValues))
+	for i, forecast := range response.ForecasterValues {
+		field := fmt.Sprintf("forecasterValue[%d]", i)
+		if forecast.Worker == "" {
+			return InferenceForecastResponse{}, fmt.Errorf("%s.worker is missing", field)
+		}
+		if err := validateAddress(forecast.Worker, addressPrefix); err != nil {
+			return InferenceForecastResponse{}, fmt.Errorf("%s.worker %q is not a valid %s address: %w", field, forecast.Worker, addressPrefix, err)
+		}
+		if previous, ok := workers[forecast.Worker]; ok {
+			return InferenceForecastResponse{}, fmt.Errorf("%s.worker %s is already forecasted in forecasterValue[%d]", field, forecast.Worker, previous)
+		}
+		workers[forecast.Worker] = i
+		if forecast.Value == "" {
+			return InferenceForecastResponse{}, fmt.Errorf("%s.value is missing", field)
+		}
+		if _, err := parseOutputValue(field+".value", string(forecast.Value)); err != nil {
+			return InferenceForecastResponse{}, err
+		}
+	}
+	return response, nil
+}
+
+// parseValueBundle parses the output of a function in reputer mode, a ValueBundle in an envelope or,
+// in the legacy format, JSON encoded in the value of a ReputerWASMResponse. Both are checked like the
+// output in worker mode: anything else in the output, values which are not finite numbers, values of
+// workers which are not addresses with the prefix and values of the same worker twice in a list are
+// rejected.
+func parseValueBundle(stdout string, addressPrefix string) (ValueBundle, error) {
+	var bundle ValueBundle
+	payload, err := unwrapOutput(stdout, WorkerModeReputer)
+	if err != nil {
+		return ValueBundle{}, err
+	}
+	if payload != nil {
+		if err := decodeStrict(payload, &bundle); err != nil {
+			return ValueBundle{}, fmt.Errorf("output envelope payload is not a value bundle: %w", err)
+		}
+	} else {
+		var response ReputerWASMResponse
+		if err := decodeStrict([]byte(stdout), &response); err != nil {
+			return ValueBundle{}, fmt.Errorf("output is not a reputer response: %w", err)
+		}
+		if err := decodeStrict([]byte(response.Value), &bundle); err != nil {
+			return ValueBundle{}, fmt.Errorf("value of the reputer response is not a value bundle: %w", err)
+		}
+	}
+
+	if err := validateBundleValue("combinedValue", bundle.CombinedValue); err != nil {
+		return ValueBundle{}, err
+	}
+	if err := validateBundleValue("naiveValue", bundle.NaiveValue); err != nil {
+		return ValueBundle{}, err
+	}
+	lists := []struct {
+		field  string
+		values []NodeValue
+	}{
+		{"infererValues", bundle.InfererValues},
+		{"forecasterValues", bundle.ForecasterValues},
+		{"oneOutInfererValues", bundle.OneOutInfererValues},
+		{"oneOutForecasterValues", bundle.OneOutForecasterValues},
+		{"oneInForecasterValues", bundle.OneInForecasterValues},
+	}
+	for _, list := range lists {
+		if err := validateNodeValues(list.field, list.values, addressPrefix); err != nil {
+			return ValueBundle{}, err
+		}
+	}
+	return bundle, nil
+}
+
+// validateBundleValue checks a value of a value bundle not attributed to a worker, which is required.
+func validateBundleValue(field string, value DecimalValue) error {
+	if value == "" {
+		return fmt.Errorf("%s is missing", field)
+	}
+	_, err := parseOutputValue(field, string(value))
+	return err
+}
+
+// validateNodeValues checks the values of workers in the list field of a value bundle.
+func validateNodeValues(field string, values []NodeValue, addressPrefix string) error {
+	workers := make(map[string]int, len(values))
+	for i, value := range values {
+		item := fmt.Sprintf("%s[%d]", field, i)
+		if value.Worker == "" {
+			return fmt.Errorf("%s.worker is missing", item)
+		}
+		if err := validateAddress(value.Worker, addressPrefix); err != nil {
+			return fmt.Errorf("%s.worker %q is not a valid %s address: %w", item, value.Worker, addressPrefix, err)
+		}
+		if previous, ok := workers[value.Worker]; ok {
+			return fmt.Errorf("%s.worker %s is already in %s[%d]", item, value.Worker, field, previous)
+		}
+		workers[value.Worker] = i
+		if value.Value == "" {
+			return fmt.Errorf("%s.value is missing", item)
+		}
+		if _, err := parseOutputValue(item+".value", string(value.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOutputValue parses a finite decimal value output by a function.
+func parseOutputValue(field string, value string) (alloraMath.Dec, error) {
+	dec, err := alloraMath.NewDecFromString(value)
+	if errors.Is(err, alloraMath.ErrInfiniteString) || (err == nil && (dec.IsNaN() || !dec.IsFinite())) {
+		return alloraMath.Dec{}, fmt.Errorf("%s %q is not finite", field, value)
+	}
+	if err != nil {
+		return alloraMath.Dec{}, fmt.Errorf("%s %q is not a decimal number", field, value)
+	}
+	return dec, nil
+}
+
+// validateAddress checks that the address is a bech32 account address with the prefix.
+func validateAddress(address string, prefix string) error {
+	bz, err := sdktypes.GetFromBech32(address, prefix)
+	if err != nil {
+		return err
+	}
+	return sdktypes.VerifyAddressFormat(bz)
+}
+
+// invalidOutput marks the result of an execution whose output is invalid, so that its output is not
+// aggregated and the head reports the error with the failures of the workers.
+func invalidOutput(result execute.Result, err error) execute.Result {
+	result.Code = codes.Invalid
+	result.Result.Stdout = ""
+	result.Result.Stderr = err.Error()
+	return result
+}
+
+// Significant digits alloraMath computes with. Values output with more would be rounded.
+const decimalPrecision = 34
+
+// DecimalValue is a decimal value output by a function, given as a JSON string or number. Numbers
+// are kept as written, never going through a float, and values having more significant digits than
+// alloraMath computes with are rejected.
+type DecimalValue string
+
+func (v *DecimalValue) UnmarshalJSON(data []byte) error {
+	var value string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	} else if string(data) == "null" {
+		return nil
+	} else {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("value %s is neither a decimal string nor a number", data)
+		}
+		value = number.String()
+	}
+	if _, err := alloraMath.NewDecFromString(value); err == nil {
+		if digits := significantDigits(value); digits > decimalPrecision {
+			return fmt.Errorf("value %s has %d significant digits, more than the %d kept", value, digits, decimalPrecision)
+		}
+	}
+	*v = DecimalValue(value)
+	return nil
+}
+
+// significantDigits counts the digits of a decimal number, without leading and trailing zeros.
+func significantDigits(value string) int {
+	value = strings.TrimLeft(value, "+-")
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.Replace(value, ".", "", 1)
+	return len(strings.Trim(value, "0"))
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInferenceForecastResponse(t *testing.T) {
+	worker1 := sdktypes.MustBech32ifyAddressBytes("allo", bytes.Repeat([]byte{1}, 20))
+	worker2 := sdktypes.MustBech32ifyAddressBytes("allo", bytes.Repeat([]byte{2}, 32))
+	other := sdktypes.MustBech32ifyAddressBytes("cosmos", bytes.Repeat([]byte{1}, 20))
+
+	response, err := parseInferenceForecastResponse(fmt.Sprintf(
+		`{"infererValue": "-1.5e3", "forecasterValue": [{"worker": %q, "value": "0.25"}, {"worker": %q, "value": "3"}]}`+"\n",
+		worker1, worker2,
+	), "allo")
+	require.NoError(t, err)
+	require.Equal(t, InferenceForecastResponse{
+		InfererValue:     "-1.5e3",
+		ForecasterValues: []NodeValue{{Worker: worker1, Value: "0.25"}, {Worker: worker2, Value: "3"}},
+	}, response)
+
+	_, err = parseInferenceForecastResponse(`{"infererValue": "7"}`, "allo")
+	require.NoError(t, err)
+	// Values may be numbers.
+	response, err = parseInferenceForecastResponse(fmt.Sprintf(`{"infererValue": 0.18, "forecasterValue": [{"worker": %q, "value": -2e-3}]}`, worker1), "allo")
+	require.NoError(t, err)
+	require.Equal(t, DecimalValue("0.18"), response.InfererValue)
+	require.Equal(t, DecimalValue("-2e-3"), response.ForecasterValues[0].Value)
+	_, err = parseInferenceForecastResponse(fmt.Sprintf(`{"forecasterValue": [{"worker": %q, "value": "1"}]}`, worker1), "allo")
+	require.NoError(t, err)
+
+	for stdout, message := range map[string]string{
+		``:                                  "output is empty",
+		`1.5`:                               "output is not an inference and forecast object",
+		`{"infererValue": "1"`:              "output is not an inference and forecast object",
+		`{"infererValue": "1", "extra": 1}`: `unknown field "extra"`,
+		`{"infererValue": "1"} {}`:          "output is not an inference and forecast object: it has data after the value",
+		`{}`:                                "output has neither infererValue nor forecasterValue",
+		`{"infererValue": "", "forecasterValue": []}`:                                  "output has neither infererValue nor forecasterValue",
+		`{"infererValue": true}`:                                                       "value true is neither a decimal string nor a number",
+		`{"infererValue": 0.12345678901234567890123456789012345}`:                      "has 35 significant digits",
+		`{"infererValue": "abc"}`:                                                      `infererValue "abc" is not a decimal number`,
+		`{"infererValue": "NaN"}`:                                                      `infererValue "NaN" is not finite`,
+		`{"infererValue": "Infinity"}`:                                                 `infererValue "Infinity" is not finite`,
+		`{"infererValue": "1", "forecasterValue": [{"value": "1"}]}`:                   "forecasterValue[0].worker is missing",
+		`{"forecasterValue": [{"worker": "allo1xyz", "value": "1"}]}`:                  `forecasterValue[0].worker "allo1xyz" is not a valid allo address`,
+		fmt.Sprintf(`{"forecasterValue": [{"worker": %q, "value": "1"}]}`, other):      "is not a valid allo address",
+		fmt.Sprintf(`{"forecasterValue": [{"worker": %q}]}`, worker1):                  "forecasterValue[0].value is missing",
+		fmt.Sprintf(`{"forecasterValue": [{"worker": %q, "value": "-inf"}]}`, worker1): `forecasterValue[0].value "-inf" is not finite`,
+		fmt.Sprintf(`{"forecasterValue": [{"worker": %q, "value": "1"}, {"worker": %q, "value": "2"}, {"worker": %q, "value": "3"}]}`, worker1, worker2, worker1): "forecasterValue[2].worker " + worker1 + " is already forecasted in forecasterValue[0]",
+	} {
+		_, err := parseInferenceForecastResponse(stdout, "allo")
+		require.ErrorContains(t, err, message, stdout)
+	}
+}
+
+func TestInvalidOutput(t *testing.T) {
+	result := execute.Result{Code: codes.OK, Result: execute.RuntimeOutput{Stdout: "garbage", Stderr: "warning"}, RequestID: "r"}
+	result = invalidOutput(result, fmt.Errorf("invalid worker output: output is empty"))
+	require.Equal(t, execute.Result{
+		Code:      codes.Invalid,
+		Result:    execute.RuntimeOutput{Stderr: "invalid worker output: output is empty"},
+		RequestID: "r",
+	}, result)
+}
+
+func TestDecimalValueUnmarshalJSON(t *testing.T) {
+	for data, want := range map[string]DecimalValue{
+		`"0.18"`:                               "0.18",
+		`0.18`:                                 "0.18",
+		`-12`:                                  "-12",
+		`1.5e-7`:                               "1.5e-7",
+		`null`:                                 "",
+		`""`:                                   "",
+		`"abc"`:                                "abc", // left for validation
+		`0.1234567890123456789012345678901234`: "0.1234567890123456789012345678901234",
+		`12345678901234567890123456789012340000000`: "12345678901234567890123456789012340000000",
+		`0.000000000000000000000000000000000000001`: "0.000000000000000000000000000000000000001",
+	} {
+		var v DecimalValue
+		require.NoError(t, json.Unmarshal([]byte(data), &v), data)
+		require.Equal(t, want, v, data)
+	}
+
+	for _, data := range []string{
+		`true`, `{}`, `[1]`,
+		`0.12345678901234567890123456789012345`,
+		`"123456789012345678901234567890123456789"`,
+		`1.00000000000000000000000000000000001e10`,
+	} {
+		var v DecimalValue
+		require.Error(t, json.Unmarshal([]byte(data), &v), data)
+	}
+
+	// Numbers are kept as written, where a float would round them.
+	var bundle ValueBundle
+	require.NoError(t, json.Unmarshal([]byte(`{"combinedValue": 0.1000000000000000055511151231257827, "infererValues": [{"worker": "a", "value": 9007199254740993}]}`), &bundle))
+	require.Equal(t, DecimalValue("0.1000000000000000055511151231257827"), bundle.CombinedValue)
+	require.Equal(t, DecimalValue("9007199254740993"), bundle.InfererValues[0].Value)
+
+	// Values are still marshalled as strings.
+	data, err := json.Marshal(NodeValue{Worker: "a", Value: "0.18"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"worker": "a", "value": "0.18"}`, string(data))
+}
+
+func TestOutputEnvelope(t *testing.T) {
+	worker := sdktypes.MustBech32ifyAddressBytes("allo", bytes.Repeat([]byte{1}, 20))
+
+	response, err := parseInferenceForecastResponse(fmt.Sprintf(
+		`{"version": 2, "kind": "worker", "payload": {"infererValue": 1.5, "forecasterValue": [{"worker": %q, "value": "2"}]}}`, worker,
+	), "allo")
+	require.NoError(t, err)
+	require.Equal(t, InferenceForecastResponse{
+		InfererValue:     "1.5",
+		ForecasterValues: []NodeValue{{Worker: worker, Value: "2"}},
+	}, response)
+	// The payload is validated as the legacy output is.
+	_, err = parseInferenceForecastResponse(`{"version": 2, "kind": "worker", "payload": {"infererValue": "x"}}`, "allo")
+	require.ErrorContains(t, err, `infererValue "x" is not a decimal number`)
+
+	bundle, err := parseValueBundle(fmt.Sprintf(
+		`{"version": 2, "kind": "reputer", "payload": {"combinedValue": 0.5, "naiveValue": "0.7", "infererValues": [{"worker": %q, "value": 1}]}}`, worker,
+	), "allo")
+	require.NoError(t, err)
+	require.Equal(t, ValueBundle{CombinedValue: "0.5", NaiveValue: "0.7", InfererValues: []NodeValue{{Worker: worker, Value: "1"}}}, bundle)
+
+	// Legacy reputer output.
+	bundle, err = parseValueBundle(`{"value": "{\"combinedValue\": \"0.5\", \"naiveValue\": 0.7}"}`, "allo")
+	require.NoError(t, err)
+	require.Equal(t, ValueBundle{CombinedValue: "0.5", NaiveValue: "0.7"}, bundle)
+
+	for stdout, message := range map[string]string{
+		`{"version": 3, "kind": "reputer", "payload": {}}`:                             "output envelope version 3 is not supported, expected 2",
+		`{"version": 1, "kind": "reputer", "payload": {}}`:                             "output envelope version 1 is not supported",
+		`{"version": "2", "kind": "reputer", "payload": {}}`:                           "output is not an envelope",
+		`{"version": 2, "kind": "worker", "payload": {}}`:                              `output envelope kind "worker" does not match the reputer mode of the node`,
+		`{"version": 2, "kind": "reputer"}`:                                            "output envelope has no payload",
+		`{"version": 2, "kind": "reputer", "payload": null}`:                           "output envelope has no payload",
+		`{"version": 2, "kind": "reputer", "payload": {}, "extra": 1}`:                 `unknown field "extra"`,
+		`{"version": 2, "kind": "reputer", "payload": {"combinedValue": "1", "x": 1}}`: "output envelope payload is not a value bundle",
+		`{"version": 2, "kind": "reputer", "payload": "{}"}`:                           "output envelope payload is not a value bundle",
+		`{"value": 1}`:   "output is not a reputer response",
+		`{"value": "{"}`: "value of the reputer response is not a value bundle",
+		// The legacy output is as strict as the envelope.
+		`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1}", "x": 1}`:   `unknown field "x"`,
+		`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1, \"x\": 1}"}`: "value of the reputer response is not a value bundle",
+		`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1} {}"}`:        "it has data after the value",
+		// Values and workers are checked whatever the format.
+		`{"value": "{\"naiveValue\": 1}"}`:                             "combinedValue is missing",
+		`{"value": "{\"combinedValue\": \"NaN\", \"naiveValue\": 1}"}`: `combinedValue "NaN" is not finite`,
+		`{"value": "{\"combinedValue\": 1, \"naiveValue\": \"x\"}"}`:   `naiveValue "x" is not a decimal number`,
+		`{"version": 2, "kind": "reputer", "payload": {"combinedValue": 1, "naiveValue": 1, "infererValues": [{"worker": "a", "value": 1}]}}`:                                              `infererValues[0].worker "a" is not a valid allo address`,
+		fmt.Sprintf(`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1, \"oneInForecasterValues\": [{\"worker\": \"%s\"}]}"}`, worker):                                                   "oneInForecasterValues[0].value is missing",
+		fmt.Sprintf(`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1, \"forecasterValues\": [{\"worker\": \"%s\", \"value\": \"Inf\"}]}"}`, worker):                                    `forecasterValues[0].value "Inf" is not finite`,
+		fmt.Sprintf(`{"value": "{\"combinedValue\": 1, \"naiveValue\": 1, \"infererValues\": [{\"worker\": \"%s\", \"value\": 1}, {\"worker\": \"%s\", \"value\": 2}]}"}`, worker, worker): "infererValues[1].worker " + worker + " is already in infererValues[0]",
+	} {
+		_, err := parseValueBundle(stdout, "allo")
+		require.ErrorContains(t, err, message, stdout)
+	}
+	_, err = parseInferenceForecastResponse(`{"version": 2, "kind": "reputer", "payload": {"infererValue": "1"}}`, "allo")
+	require.ErrorContains(t, err, `output envelope kind "reputer" does not match the worker mode of the node`)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/allora-network/b7s/models/execute"
+)
+
+const redacted = "[REDACTED]"
+
+// Environment variables secrets are read from when given neither as flag nor as file.
+const (
+	envRestoreMnemonic   = "ALLORA_CHAIN_RESTORE_MNEMONIC"
+	envAccountPassphrase = "ALLORA_CHAIN_ACCOUNT_PASSWORD"
+)
+
+// Parts of environment variable names that mark their values as sensitive.
+var sensitiveNameParts = []string{"SECRET", "PASSWORD", "PASSPHRASE", "MNEMONIC", "PRIVATE_KEY", "PRIVKEY", "TOKEN", "API_KEY", "APIKEY"}
+
+// Secret holds a sensitive setting such as a mnemonic or a passphrase. It prints and marshals as
+// [REDACTED] so that it does not leak through logs or config dumps. Copies share the value, so
+// wiping one wipes them all.
+type Secret struct {
+	v *secretValue
+}
+
+type secretValue struct {
+	lock sync.RWMutex
+	b    []byte
+}
+
+// NewSecret wraps the given bytes, which the secret takes ownership of.
+func NewSecret(b []byte) Secret {
+	return Secret{v: &secretValue{b: b}}
+}
+
+// Empty tells whether the secret is unset or wiped.
+func (s Secret) Empty() bool {
+	if s.v == nil {
+		return true
+	}
+	s.v.lock.RLock()
+	defer s.v.lock.RUnlock()
+	return len(s.v.b) == 0
+}
+
+// Reveal returns the secret value. The returned string is a copy that cannot be wiped,
+// so it should only be handed to the code that needs it.
+func (s Secret) Reveal() string {
+	if s.v == nil {
+		return ""
+	}
+	s.v.lock.RLock()
+	defer s.v.lock.RUnlock()
+	return string(s.v.b)
+}
+
+// Wipe zeroes the secret value once it is not needed anymore.
+func (s Secret) Wipe() {
+	if s.v == nil {
+		return
+	}
+	s.v.lock.Lock()
+	defer s.v.lock.Unlock()
+	for i := range s.v.b {
+		s.v.b[i] = 0
+	}
+	s.v.b = nil
+}
+
+func (s Secret) String() string {
+	if s.Empty() {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// UnmarshalJSON reads the secret from a JSON string, for secrets kept in config files.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+	*s = Secret{}
+	if value != "" {
+		*s = NewSecret([]byte(value))
+	}
+	return nil
+}
+
+// loadSecret reads a secret from a flag value, a file or an environment variable, in that order of
+// preference. Giving both a flag value and a file is an error. Surrounding whitespace is trimmed.
+func loadSecret(value string, file string, env string) (Secret, error) {
+	if value != "" && file != "" {
+		return Secret{}, errors.New("secret given both as value and as file")
+	}
+
+	var b []byte
+	switch {
+	case value != "":
+		b = []byte(value)
+	case file != "":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			// The path error does not contain the file content.
+			return Secret{}, err
+		}
+		b = append([]byte(nil), bytes.TrimSpace(content)...)
+		for i := range content {
+			content[i] = 0
+		}
+	default:
+		b = []byte(os.Getenv(env))
+	}
+
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return Secret{}, nil
+	}
+	return NewSecret(b), nil
+}
+
+// Redactor replaces known secret values in log output.
+type Redactor struct {
+	lock    sync.RWMutex
+	secrets []Secret
+}
+
+// Add registers a secret to be redacted for as long as it is not wiped.
+func (r *Redactor) Add(secrets ...Secret) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, s := range secrets {
+		if !s.Empty() {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+}
+
+// Redact returns p with every secret value replaced, as is or escaped in a JSON string as log
+// fields are.
+func (r *Redactor) Redact(p []byte) []byte {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, s := range r.secrets {
+		s.v.lock.RLock()
+		if len(s.v.b) > 0 {
+			p = redactValue(p, s.v.b)
+			for _, escaped := range jsonEscapedForms(s.v.b) {
+				p = redactValue(p, escaped)
+				clear(escaped)
+			}
+		}
+		s.v.lock.RUnlock()
+	}
+	return p
+}
+
+func redactValue(p []byte, value []byte) []byte {
+	if !bytes.Contains(p, value) {
+		return p
+	}
+	return bytes.ReplaceAll(p, value, []byte(redacted))
+}
+
+// jsonEscapedForms returns the value as escaped in a JSON string, with and without HTML escaping,
+// when it differs from the value itself.
+func jsonEscapedForms(value []byte) [][]byte {
+	var forms [][]byte
+	for _, escapeHTML := range []bool{true, false} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		if enc.Encode(string(value)) != nil {
+			continue
+		}
+		// Strip the quotes and the newline the encoder ends with.
+		escaped := buf.Bytes()[1 : buf.Len()-2]
+		if bytes.Equal(escaped, value) || (len(forms) > 0 && bytes.Equal(escaped, forms[0])) {
+			clear(buf.Bytes())
+			continue
+		}
+		forms = append(forms, escaped)
+	}
+	return forms
+}
+
+// Writer wraps w so that secrets are redacted from everything written to it.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactingWriter{w: w, r: r}
+}
+
+type redactingWriter struct {
+	w io.Writer
+	r *Redactor
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	_, err := w.w.Write(w.r.Redact(p))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// isSensitiveName tells whether an environment variable name suggests a secret value.
+func isSensitiveName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(upper, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactEnvironment returns a copy of the environment with the values of sensitive variables redacted.
+func redactEnvironment(env []execute.EnvVar) []execute.EnvVar {
+	out := make([]execute.EnvVar, len(env))
+	for i, v := range env {
+		out[i] = v
+		if isSensitiveName(v.Name) {
+			out[i].Value = redacted
+		}
+	}
+	return out
+}
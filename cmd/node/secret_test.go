@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "palace cube bitter light woman side pave cereal donor bronze twice work"
+
+func TestSecretIsRedacted(t *testing.T) {
+	cfg := AppChainConfig{AddressKeyName: "alice", AddressRestoreMnemonic: NewSecret([]byte(testMnemonic))}
+
+	for _, out := range []string{
+		fmt.Sprintf("%v", cfg),
+		fmt.Sprintf("%+v", cfg),
+		fmt.Sprintf("%#v", cfg),
+		cfg.AddressRestoreMnemonic.String(),
+	} {
+		require.NotContains(t, out, "palace")
+	}
+
+	dump, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.NotContains(t, string(dump), "palace")
+	require.Contains(t, string(dump), `"AddressRestoreMnemonic":"[REDACTED]"`)
+}
+
+func TestSecretWipe(t *testing.T) {
+	secret := NewSecret([]byte(testMnemonic))
+	cfg := AppChainConfig{AddressRestoreMnemonic: secret}
+	require.Equal(t, testMnemonic, cfg.AddressRestoreMnemonic.Reveal())
+
+	// Copies share the value.
+	secret.Wipe()
+	require.True(t, cfg.AddressRestoreMnemonic.Empty())
+	require.Empty(t, cfg.AddressRestoreMnemonic.Reveal())
+	require.True(t, Secret{}.Empty())
+}
+
+func TestLoadSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mnemonic")
+	require.NoError(t, os.WriteFile(file, []byte(testMnemonic+"\n"), 0600))
+	t.Setenv("TEST_SECRET", "from env")
+
+	secret, err := loadSecret("", file, "TEST_SECRET")
+	require.NoError(t, err)
+	require.Equal(t, testMnemonic, secret.Reveal())
+
+	secret, err = loadSecret("from flag", "", "TEST_SECRET")
+	require.NoError(t, err)
+	require.Equal(t, "from flag", secret.Reveal())
+
+	secret, err = loadSecret("", "", "TEST_SECRET")
+	require.NoError(t, err)
+	require.Equal(t, "from env", secret.Reveal())
+
+	secret, err = loadSecret("", "", "TEST_SECRET_UNSET")
+	require.NoError(t, err)
+	require.True(t, secret.Empty())
+
+	_, err = loadSecret("from flag", file, "TEST_SECRET")
+	require.Error(t, err)
+	_, err = loadSecret("", filepath.Join(t.TempDir(), "missing"), "TEST_SECRET")
+	require.Error(t, err)
+}
+
+func TestRedactor(t *testing.T) {
+	secret := NewSecret([]byte(testMnemonic))
+	redactor := &Redactor{}
+	redactor.Add(secret, Secret{})
+
+	var buf bytes.Buffer
+	w := redactor.Writer(&buf)
+	line := []byte("invalid mnemonic " + testMnemonic + "\n")
+	n, err := w.Write(line)
+	require.NoError(t, err)
+	require.Equal(t, len(line), n)
+	require.Equal(t, "invalid mnemonic [REDACTED]\n", buf.String())
+}
+
+func TestRedactorEscapedSecret(t *testing.T) {
+	redactor := &Redactor{}
+	redactor.Add(NewSecret([]byte(`pass"word<\`)))
+
+	// Log fields and JSON bodies hold the secret escaped.
+	var buf bytes.Buffer
+	log := zerolog.New(redactor.Writer(&buf))
+	log.Info().Str("passphrase", `pass"word<\`).Msg("")
+	require.Equal(t, `{"level":"info","passphrase":"[REDACTED]"}`+"\n", buf.String())
+	body, err := json.Marshal(map[string]string{"passphrase": `pass"word<\`})
+	require.NoError(t, err)
+	require.Equal(t, `{"passphrase":"[REDACTED]"}`, string(redactor.Redact(body)))
+	require.Equal(t, "[REDACTED]", string(redactor.Redact([]byte(`pass"word<\`))))
+}
+
+func TestRedactEnvironment(t *testing.T) {
+	env := []execute.EnvVar{
+		{Name: "TOPIC_ID", Value: "1"},
+		{Name: "UPSTREAM_API_KEY", Value: "abc"},
+		{Name: "db_password", Value: "hunter2"},
+	}
+
+	redactedEnv := redactEnvironment(env)
+	require.Equal(t, "1", redactedEnv[0].Value)
+	require.Equal(t, redacted, redactedEnv[1].Value)
+	require.Equal(t, redacted, redactedEnv[2].Value)
+	// The request itself is left untouched.
+	require.Equal(t, "abc", env[1].Value)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/allora-network/b7s/api"
+	"github.com/labstack/echo/v4"
+)
+
+// Interval of the comments keeping the stream open while waiting for the chain submission.
+const streamKeepAliveInterval = 15 * time.Second
+
+// StreamEvent is a phase of an execution streamed to the client, as the data of a server-sent event
+// of the same name. Only phases are streamed: b7s returns the roll call peers and the results of the
+// workers all at once with the aggregated results, which carry them, and the submitted phase is only
+// known once the transaction is found on chain, right before the confirmed phase.
+type StreamEvent struct {
+	Event      string           `json:"event"`
+	Done       bool             `json:"done"`
+	Response   *ExecuteResponse `json:"response,omitempty"`
+	Submission *ChainSubmission `json:"submission,omitempty"`
+}
+
+// streamEvent returns the event of an execution phase.
+func streamEvent(p ExecutionProgress) StreamEvent {
+	return StreamEvent{
+		Event:      p.Phase,
+		Done:       p.Done,
+		Response:   p.Response,
+		Submission: p.Submission,
+	}
+}
+
+// createStreamExecutor executes the request and streams its progress as server-sent events, until
+// the results are submitted to the chain or the execution fails.
+func createStreamExecutor(a api.API, history *HistoryStore, admission *Admission) func(ctx echo.Context) error {
+
+	return func(ctx echo.Context) error {
+
+		// Unpack the API request.
+		var req ExecuteRequest
+		err := ctx.Bind(&req)
+		if err != nil {
+			return badRequest(fmt.Sprintf("could not unpack request: %s", err))
+		}
+
+		release, err := admission.Acquire(ctx.Request().Context())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		}
+
+		// Room for every phase, so that reports never wait for the client.
+		progress := make(chan ExecutionProgress, 8)
+		go func() {
+			defer release()
+			followExecution(ctx.Request().Context(), a, history, req, func(p ExecutionProgress) {
+				progress <- p
+			})
+		}()
+
+		res := ctx.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set(echo.HeaderConnection, "keep-alive")
+		// Keep proxies from buffering the events.
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+		res.Flush()
+
+		keepAlive := time.NewTicker(streamKeepAliveInterval)
+		defer keepAlive.Stop()
+		id := 0
+		for {
+			select {
+			case p := <-progress:
+				id++
+				err := writeStreamEvent(res, id, streamEvent(p))
+				if err != nil {
+					return nil
+				}
+				res.Flush()
+				if p.Done {
+					return nil
+				}
+			case <-keepAlive.C:
+				_, err := fmt.Fprint(res, ": keep-alive\n\n")
+				if err != nil {
+					return nil
+				}
+				res.Flush()
+			case <-ctx.Request().Context().Done():
+				return nil
+			}
+		}
+	}
+}
+
+// writeStreamEvent writes the event in the server-sent events format.
+func writeStreamEvent(res *echo.Response, id int, event StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", id, event.Event, data)
+	return err
+}
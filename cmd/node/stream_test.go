@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/allora-network/b7s/api"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamExecutor(t *testing.T) {
+	handler := createStreamExecutor(*api.New(zerolog.Nop(), &fakeNode{}), nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/functions/execute/stream",
+		strings.NewReader(`{"function_id":"bafy","method":"main.wasm","topic":"1"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler(echo.New().NewContext(req, rec)))
+	require.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+
+	// Without submission tracking, the stream ends with the aggregated results.
+	var events []string
+	for _, match := range regexp.MustCompile(`(?m)^event: (\w+)$`).FindAllStringSubmatch(rec.Body.String(), -1) {
+		events = append(events, match[1])
+	}
+	require.Equal(t, []string{AsyncPhaseRollCall, AsyncPhaseAggregated}, events)
+	// The workers' results come with the aggregated results.
+	require.Contains(t, rec.Body.String(), `"event":"aggregated","done":true`)
+	require.Contains(t, rec.Body.String(), `"3sdfvR"`)
+}
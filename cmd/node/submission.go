@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/allora-network/allora-inference-base/pkg/headapi"
+	"github.com/allora-network/b7s/models/codes"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog"
+)
+
+// Statuses of the chain submission of an execution.
+const (
+	SubmissionPending   = "pending"   // looking for the transaction
+	SubmissionConfirmed = "confirmed" // included in a block and successful
+	SubmissionFailed    = "failed"    // included in a block, but failed
+	SubmissionNotFound  = "not_found" // no transaction found before the lookup timed out
+)
+
+const (
+	defaultSubmissionTimeout = 5 * time.Minute
+	submissionPollInterval   = 10 * time.Second
+	submissionSearchPerPage  = 100
+	submissionSearchMaxPages = 10
+	submissionSearchTimeout  = 30 * time.Second
+)
+
+// ChainSubmission is the outcome of the leader submitting the results of an execution to the chain.
+type ChainSubmission struct {
+	Status   string   `json:"status"`
+	TxHashes []string `json:"tx_hashes,omitempty"`
+	Height   int64    `json:"height,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	// Error code of the last lookup, chain_unavailable if the chain could not be reached.
+	ErrorCode string    `json:"error_code,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SubmissionTracker looks up the transactions in which the leader of an execution submitted its
+// results. The head does not take part in the submission, so it searches the chain for bulk
+// payloads of the topic and nonce of the request, read only.
+type SubmissionTracker struct {
+	endpoints *EndpointPool
+	timeout   time.Duration
+	log       zerolog.Logger
+	ctx       context.Context
+}
+
+// NewSubmissionTracker creates a tracker searching the chain through the given endpoints until ctx is done.
+func NewSubmissionTracker(ctx context.Context, endpoints *EndpointPool, timeout time.Duration, log zerolog.Logger) *SubmissionTracker {
+	return &SubmissionTracker{
+		endpoints: endpoints,
+		timeout:   timeout,
+		log:       log,
+		ctx:       ctx,
+	}
+}
+
+// Trackable returns the topic and worker mode of an execution whose results are submitted to the
+// chain, which are those of successful executions carrying the block height of the topic nonce.
+func (t *SubmissionTracker) Trackable(rec ExecutionRecord) (uint64, string, bool) {
+	if rec.Code != codes.OK || rec.ErrorCode != "" || rec.Nonce <= 0 {
+		return 0, "", false
+	}
+	topic, err := ParseRequestTopic(rec.Topic)
+	if err != nil {
+		return 0, "", false
+	}
+	return topic.ID, topic.Mode, true
+}
+
+// Track polls the chain for the submission until it is found or the lookup times out, reporting
+// every change to update.
+func (t *SubmissionTracker) Track(requestID string, topicId uint64, mode string, nonce int64, update func(ChainSubmission)) {
+	go func() {
+		log := t.log.With().Str("request", requestID).Uint64("topic", topicId).Str("mode", mode).Int64("nonce", nonce).Logger()
+		ctx, cancel := context.WithTimeout(t.ctx, t.timeout)
+		defer cancel()
+
+		var last ChainSubmission
+		for {
+			sub, err := t.find(ctx, topicId, mode, nonce)
+			if err != nil {
+				log.Debug().Err(err).Msg("could not search chain submission")
+			}
+			if sub.Status != SubmissionPending {
+				log.Info().Str("status", sub.Status).Strs("tx_hashes", sub.TxHashes).Msg("found chain submission")
+				update(sub)
+				return
+			}
+			last = sub
+
+			if !sleepContext(ctx, submissionPollInterval) {
+				last.Status = SubmissionNotFound
+				last.UpdatedAt = time.Now()
+				log.Info().Msg("chain submission not found")
+				update(last)
+				return
+			}
+		}
+	}()
+}
+
+// find searches the chain for bulk payloads of the topic nonce. The submission is confirmed once a
+// matching transaction succeeded and failed if all matching transactions failed.
+func (t *SubmissionTracker) find(ctx context.Context, topicId uint64, mode string, nonce int64) (ChainSubmission, error) {
+	sub := ChainSubmission{Status: SubmissionPending, UpdatedAt: time.Now()}
+
+	if !t.endpoints.Healthy() {
+		err := t.endpoints.Probe(ctx)
+		if err != nil {
+			return sub.unavailable(err)
+		}
+	}
+	client := t.endpoints.Primary()
+	if client == nil {
+		return sub.unavailable(errNoHealthyEndpoint)
+	}
+
+	msgTypeURL := sdktypes.MsgTypeURL(&emissionstypes.MsgInsertBulkWorkerPayload{})
+	if mode == WorkerModeReputer {
+		msgTypeURL = sdktypes.MsgTypeURL(&emissionstypes.MsgInsertBulkReputerPayload{})
+	}
+	query := fmt.Sprintf("message.action='%s' AND tx.height>=%d", msgTypeURL, nonce)
+
+	searchCtx, cancel := context.WithTimeout(ctx, submissionSearchTimeout)
+	defer cancel()
+
+	var failed []string
+	perPage := submissionSearchPerPage
+	for page := 1; page <= submissionSearchMaxPages; page++ {
+		res, err := client.RPC.TxSearch(searchCtx, query, false, &page, &perPage, "asc")
+		if err != nil {
+			return sub.unavailable(err)
+		}
+		for _, tx := range res.Txs {
+			if !bulkPayloadTxMatches(tx.Tx, mode, topicId, nonce) {
+				continue
+			}
+			hash := strings.ToUpper(tx.Hash.String())
+			if tx.TxResult.Code != 0 {
+				failed = append(failed, hash)
+				continue
+			}
+			sub.Status = SubmissionConfirmed
+			sub.TxHashes = append(sub.TxHashes, hash)
+			sub.Height = max(sub.Height, tx.Height)
+		}
+		if page*perPage >= res.TotalCount {
+			break
+		}
+	}
+
+	if sub.Status == SubmissionPending && len(failed) > 0 {
+		sub.Status = SubmissionFailed
+		sub.TxHashes = failed
+	}
+	return sub, nil
+}
+
+// unavailable records that the chain could not be reached to look up the submission.
+func (s ChainSubmission) unavailable(err error) (ChainSubmission, error) {
+	s.Error = err.Error()
+	s.ErrorCode = headapi.ErrorChainUnavailable
+	return s, err
+}
+
+// bulkPayloadTxMatches tells whether the raw tx carries a bulk payload of the worker mode for the topic nonce.
+// Msgs are decoded directly, the emissions types are not registered with the client codec.
+func bulkPayloadTxMatches(rawTx []byte, mode string, topicId uint64, nonce int64) bool {
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(rawTx); err != nil {
+		return false
+	}
+	var body txtypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return false
+	}
+
+	for _, msgAny := range body.Messages {
+		switch {
+		case mode == WorkerModeReputer && msgAny.TypeUrl == sdktypes.MsgTypeURL(&emissionstypes.MsgInsertBulkReputerPayload{}):
+			var msg emissionstypes.MsgInsertBulkReputerPayload
+			if msg.Unmarshal(msgAny.Value) != nil {
+				continue
+			}
+			if msg.TopicId == topicId && msg.ReputerRequestNonce != nil && msg.ReputerRequestNonce.ReputerNonce != nil &&
+				msg.ReputerRequestNonce.ReputerNonce.BlockHeight == nonce {
+				return true
+			}
+		case mode != WorkerModeReputer && msgAny.TypeUrl == sdktypes.MsgTypeURL(&emissionstypes.MsgInsertBulkWorkerPayload{}):
+			var msg emissionstypes.MsgInsertBulkWorkerPayload
+			if msg.Unmarshal(msgAny.Value) != nil {
+				continue
+			}
+			if msg.TopicId == topicId && msg.Nonce != nil && msg.Nonce.BlockHeight == nonce {
+				return true
+			}
+		}
+	}
+	return false
+}
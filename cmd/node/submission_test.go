@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkPayloadTxMatches(t *testing.T) {
+	msg, err := codectypes.NewAnyWithValue(&emissionstypes.MsgInsertBulkWorkerPayload{
+		TopicId: 1,
+		Nonce:   &emissionstypes.Nonce{BlockHeight: 100},
+	})
+	require.NoError(t, err)
+	body, err := (&txtypes.TxBody{Messages: []*codectypes.Any{msg}}).Marshal()
+	require.NoError(t, err)
+	tx, err := (&txtypes.TxRaw{BodyBytes: body}).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.MsgTypeURL(&emissionstypes.MsgInsertBulkWorkerPayload{}), msg.TypeUrl)
+
+	require.True(t, bulkPayloadTxMatches(tx, WorkerModeWorker, 1, 100))
+	require.False(t, bulkPayloadTxMatches(tx, WorkerModeWorker, 2, 100))
+	require.False(t, bulkPayloadTxMatches(tx, WorkerModeWorker, 1, 101))
+	require.False(t, bulkPayloadTxMatches(tx, WorkerModeReputer, 1, 100))
+	require.False(t, bulkPayloadTxMatches([]byte("not a tx"), WorkerModeWorker, 1, 100))
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/rs/zerolog"
+)
+
+const B7S_TOPIC_FORMAT_PREFIX = "allora-topic-"
+
+// Worker modes are lower case names, so that they can be told apart from topic IDs and separators.
+var topicModePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// TopicRef identifies the Blockless topic the workers of an Allora topic subscribe to in a worker
+// mode. The head API names it by the topic ID, followed by /{mode} for modes other than worker,
+// e.g. "1" or "1/reputer". Blockless names it allora-topic-{id}-{mode}, e.g. allora-topic-1-worker.
+type TopicRef struct {
+	ID   uint64
+	Mode string
+}
+
+// NewTopicRef returns the reference of the topic in the worker mode, worker if empty.
+func NewTopicRef(id uint64, mode string) (TopicRef, error) {
+	if mode == "" {
+		mode = WorkerModeWorker
+	}
+	if !topicModePattern.MatchString(mode) {
+		return TopicRef{}, fmt.Errorf("invalid worker mode %q", mode)
+	}
+	return TopicRef{ID: id, Mode: mode}, nil
+}
+
+// ParseTopicRef parses the Blockless name of a topic, as returned by Format.
+func ParseTopicRef(name string) (TopicRef, error) {
+	rest, ok := strings.CutPrefix(name, B7S_TOPIC_FORMAT_PREFIX)
+	if !ok {
+		return TopicRef{}, fmt.Errorf("topic %q does not start with %s", name, B7S_TOPIC_FORMAT_PREFIX)
+	}
+	id, mode, ok := strings.Cut(rest, "-")
+	if !ok || mode == "" {
+		return TopicRef{}, fmt.Errorf("topic %q has no worker mode", name)
+	}
+	topicId, err := parseTopicID(id)
+	if err != nil {
+		return TopicRef{}, fmt.Errorf("topic %q: %w", name, err)
+	}
+	return NewTopicRef(topicId, mode)
+}
+
+// WorkerTopics checks the Blockless topics a worker in the mode subscribes to, which must be for that
+// mode. Topics given by their ID alone, as before topics were named after their worker mode, are
+// deprecated and named after the mode. It returns the topics and the deprecated names given.
+func WorkerTopics(names []string, mode string) ([]string, []string, error) {
+	topics := make([]string, len(names))
+	var deprecated []string
+	for i, name := range names {
+		ref, err := ParseTopicRef(name)
+		if id, idErr := parseTopicID(name); idErr == nil {
+			deprecated = append(deprecated, name)
+			ref, err = NewTopicRef(id, mode)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if ref.Mode != mode {
+			return nil, nil, fmt.Errorf("topic %q is for the %s mode, but the node runs in the %s mode", name, ref.Mode, mode)
+		}
+		topics[i] = ref.Format()
+	}
+	return topics, deprecated, nil
+}
+
+// ParseRequestTopic parses a topic as named in head API requests and in the TOPIC_ID environment
+// variable of executions, as returned by String.
+func ParseRequestTopic(topic string) (TopicRef, error) {
+	id, mode, hasMode := strings.Cut(topic, "/")
+	topicId, err := parseTopicID(id)
+	if err != nil {
+		return TopicRef{}, fmt.Errorf("topic %q: %w", topic, err)
+	}
+	if hasMode && mode == "" {
+		return TopicRef{}, fmt.Errorf("topic %q has an empty worker mode", topic)
+	}
+	return NewTopicRef(topicId, mode)
+}
+
+// parseTopicID parses a decimal topic ID, without sign or leading zeros.
+func parseTopicID(id string) (uint64, error) {
+	if len(id) > 1 && id[0] == '0' {
+		return 0, fmt.Errorf("invalid topic ID %q", id)
+	}
+	topicId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid topic ID %q", id)
+	}
+	return topicId, nil
+}
+
+// Format returns the Blockless name of the topic.
+func (t TopicRef) Format() string {
+	return B7S_TOPIC_FORMAT_PREFIX + strconv.FormatUint(t.ID, 10) + "-" + t.Mode
+}
+
+// String returns the name of the topic in head API requests.
+func (t TopicRef) String() string {
+	if t.Mode == WorkerModeWorker {
+		return t.IDString()
+	}
+	return t.IDString() + "/" + t.Mode
+}
+
+// IDString returns the topic ID in decimal, as used in metric labels.
+func (t TopicRef) IDString() string {
+	return strconv.FormatUint(t.ID, 10)
+}
+
+// Environment variables of executions describing the topic, checked against its definition on chain.
+const (
+	envLossFunctionAllowsNegative = "LOSS_FUNCTION_ALLOWS_NEGATIVE"
+	envLossMethod                 = "LOSS_METHOD"
+	envEpochLength                = "EPOCH_LENGTH"
+	envGroundTruthLag             = "GROUND_TRUTH_LAG"
+)
+
+// Policies for executions whose environment disagrees with the topic on chain.
+const (
+	TopicEnvMismatchWarn   = "warn"   // the execution goes on with the topic on chain
+	TopicEnvMismatchReject = "reject" // the execution fails
+)
+
+const (
+	defaultTopicCacheTTL = 10 * time.Minute
+	// Max time waited for the definition of a topic when processing an execution.
+	topicQueryTimeout = 5 * time.Second
+)
+
+// TopicCache keeps the definitions of topics fetched from chain for a while, as they seldom change.
+// It is shared across reconnections. A nil cache fetches topics on every call.
+type TopicCache struct {
+	lock   sync.Mutex
+	ttl    time.Duration
+	topics map[uint64]cachedTopic
+	log    zerolog.Logger
+
+	now func() time.Time
+}
+
+type cachedTopic struct {
+	topic   *emissionstypes.Topic
+	fetched time.Time
+}
+
+// NewTopicCache returns a cache keeping topics for the ttl, or nil if the ttl is not positive.
+func NewTopicCache(ttl time.Duration, log zerolog.Logger) *TopicCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &TopicCache{ttl: ttl, topics: map[uint64]cachedTopic{}, log: log, now: time.Now}
+}
+
+// Get returns the definition of the topic, fetching it from chain if it is not cached or expired. A
+// topic that cannot be fetched again is served from the cache, even expired, until it can.
+func (c *TopicCache) Get(ctx context.Context, client emissionstypes.QueryClient, topicId uint64) (*emissionstypes.Topic, error) {
+	if c == nil {
+		return fetchTopic(ctx, client, topicId)
+	}
+
+	c.lock.Lock()
+	cached, ok := c.topics[topicId]
+	c.lock.Unlock()
+	if ok && c.now().Sub(cached.fetched) < c.ttl {
+		return cached.topic, nil
+	}
+
+	topic, err := fetchTopic(ctx, client, topicId)
+	if err != nil {
+		if ok {
+			c.log.Warn().Err(err).Uint64("topic", topicId).Time("fetched", cached.fetched).Msg("could not refresh topic, using the cached one")
+			return cached.topic, nil
+		}
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.topics[topicId] = cachedTopic{topic: topic, fetched: c.now()}
+	c.lock.Unlock()
+	return topic, nil
+}
+
+func fetchTopic(ctx context.Context, client emissionstypes.QueryClient, topicId uint64) (*emissionstypes.Topic, error) {
+	ctx, cancel := context.WithTimeout(ctx, topicQueryTimeout)
+	defer cancel()
+
+	res, err := client.GetTopic(ctx, &emissionstypes.QueryTopicRequest{TopicId: topicId})
+	if err != nil {
+		return nil, fmt.Errorf("could not get topic %d: %w", topicId, err)
+	}
+	if res.Topic == nil {
+		return nil, fmt.Errorf("topic %d not found", topicId)
+	}
+	return res.Topic, nil
+}
+
+// TopicEnvMismatch is an environment variable of an execution disagreeing with the topic on chain.
+type TopicEnvMismatch struct {
+	Name  string
+	Value string // as given in the environment
+	Chain string // as defined on chain
+}
+
+func (m TopicEnvMismatch) String() string {
+	return fmt.Sprintf("%s=%s (chain: %s)", m.Name, m.Value, m.Chain)
+}
+
+// topicEnvFallback decides whether an execution whose topic could neither be fetched from the chain
+// nor found in the cache can fall back to its environment: never under the reject policy, which only
+// trusts the chain, and otherwise counting the fallback.
+func topicEnvFallback(policy string, topicId uint64, topicLabel string, err error) error {
+	if policy == TopicEnvMismatchReject {
+		return fmt.Errorf("topic %d is not available from the chain, not using the execution environment: %w", topicId, err)
+	}
+	topicEnvFallbacks.WithLabelValues(topicLabel).Inc()
+	return nil
+}
+
+// checkTopicEnv returns the environment variables describing the topic which disagree with its
+// definition on chain. Variables which are not set are not checked.
+func checkTopicEnv(topic *emissionstypes.Topic, env []execute.EnvVar) []TopicEnvMismatch {
+	var mismatches []TopicEnvMismatch
+	for _, envVar := range env {
+		var chain string
+		var agrees bool
+		switch envVar.Name {
+		case envLossFunctionAllowsNegative:
+			// Anything but true has always meant false.
+			chain = strconv.FormatBool(topic.AllowNegative)
+			agrees = (envVar.Value == "true") == topic.AllowNegative
+		case envLossMethod:
+			chain = topic.LossMethod
+			agrees = envVar.Value == topic.LossMethod
+		case envEpochLength:
+			chain = strconv.FormatInt(topic.EpochLength, 10)
+			agrees = envVar.Value == chain
+		case envGroundTruthLag:
+			chain = strconv.FormatInt(topic.GroundTruthLag, 10)
+			agrees = envVar.Value == chain
+		default:
+			continue
+		}
+		if !agrees {
+			mismatches = append(mismatches, TopicEnvMismatch{Name: envVar.Name, Value: envVar.Value, Chain: chain})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestParseTopicRef(t *testing.T) {
+	for name, want := range map[string]TopicRef{
+		"allora-topic-1-worker":                    {ID: 1, Mode: WorkerModeWorker},
+		"allora-topic-42-reputer":                  {ID: 42, Mode: WorkerModeReputer},
+		"allora-topic-0-forecaster":                {ID: 0, Mode: "forecaster"},
+		"allora-topic-7-worker_v2":                 {ID: 7, Mode: "worker_v2"},
+		"allora-topic-18446744073709551615-worker": {ID: 18446744073709551615, Mode: WorkerModeWorker},
+	} {
+		ref, err := ParseTopicRef(name)
+		require.NoError(t, err, name)
+		require.Equal(t, want, ref)
+		require.Equal(t, name, ref.Format())
+	}
+
+	for _, name := range []string{
+		"", "a", "allora-topic-", "allora-topic-1", "allora-topic-1-", "allora-topic--worker",
+		"allora-topic-x-worker", "allora-topic-01-worker", "allora-topic-+1-worker", "allora-topic-1-Worker",
+		"allora-topic-1-rep-uter", "allora-topic-1-reputer/x", "allora-topic-18446744073709551616-worker",
+		"topic-1-reputer",
+	} {
+		_, err := ParseTopicRef(name)
+		require.Error(t, err, name)
+	}
+}
+
+func TestParseRequestTopic(t *testing.T) {
+	for topic, want := range map[string]TopicRef{
+		"1":          {ID: 1, Mode: WorkerModeWorker},
+		"1/worker":   {ID: 1, Mode: WorkerModeWorker},
+		"12/reputer": {ID: 12, Mode: WorkerModeReputer},
+		"3/custom":   {ID: 3, Mode: "custom"},
+	} {
+		ref, err := ParseRequestTopic(topic)
+		require.NoError(t, err, topic)
+		require.Equal(t, want, ref)
+	}
+	require.Equal(t, "12/reputer", TopicRef{ID: 12, Mode: WorkerModeReputer}.String())
+	require.Equal(t, "1", TopicRef{ID: 1, Mode: WorkerModeWorker}.String())
+
+	for _, topic := range []string{"", "reputer", "/reputer", "1/", "1/reputer/x", "1/Reputer", "-1", "1.5", "01"} {
+		_, err := ParseRequestTopic(topic)
+		require.Error(t, err, topic)
+	}
+}
+
+func FuzzParseTopicRef(f *testing.F) {
+	for _, seed := range []string{"allora-topic-1-worker", "allora-topic-42-reputer", "allora-topic-1", "allora-topic-", "reputer"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		ref, err := ParseTopicRef(name)
+		if err != nil {
+			return
+		}
+		// Valid names are canonical.
+		require.Equal(t, name, ref.Format())
+		parsed, err := ParseRequestTopic(ref.String())
+		require.NoError(t, err)
+		require.Equal(t, ref, parsed)
+	})
+}
+
+func FuzzParseRequestTopic(f *testing.F) {
+	for _, seed := range []string{"1", "1/reputer", "1/worker", "1/", "/reputer", "reputer", "01"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, topic string) {
+		ref, err := ParseRequestTopic(topic)
+		if err != nil {
+			return
+		}
+		parsed, err := ParseRequestTopic(ref.String())
+		require.NoError(t, err)
+		require.Equal(t, ref, parsed)
+		parsed, err = ParseTopicRef(ref.Format())
+		require.NoError(t, err)
+		require.Equal(t, ref, parsed)
+	})
+}
+
+// topicQueryClient answers GetTopic queries, counting them.
+type topicQueryClient struct {
+	emissionstypes.QueryClient
+	topic   *emissionstypes.Topic
+	err     error
+	queries int
+}
+
+func (c *topicQueryClient) GetTopic(_ context.Context, req *emissionstypes.QueryTopicRequest, _ ...grpc.CallOption) (*emissionstypes.QueryTopicResponse, error) {
+	c.queries++
+	if c.err != nil {
+		return nil, c.err
+	}
+	topic := *c.topic
+	topic.Id = req.TopicId
+	return &emissionstypes.QueryTopicResponse{Topic: &topic}, nil
+}
+
+func TestTopicCache(t *testing.T) {
+	client := &topicQueryClient{topic: &emissionstypes.Topic{LossMethod: "mse"}}
+	now := time.Unix(0, 0)
+	cache := NewTopicCache(time.Minute, zerolog.Nop())
+	cache.now = func() time.Time { return now }
+
+	topic, err := cache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), topic.Id)
+	_, err = cache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, client.queries)
+	_, err = cache.Get(context.Background(), client, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, client.queries)
+
+	// Expired topics are fetched again, or kept if they cannot be.
+	now = now.Add(time.Minute)
+	client.topic = &emissionstypes.Topic{LossMethod: "huber"}
+	topic, err = cache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	require.Equal(t, "huber", topic.LossMethod)
+	now = now.Add(time.Minute)
+	client.err = errors.New("unavailable")
+	topic, err = cache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	require.Equal(t, "huber", topic.LossMethod)
+	_, err = cache.Get(context.Background(), client, 3)
+	require.Error(t, err)
+
+	// Without a cache, topics are always fetched.
+	require.Nil(t, NewTopicCache(0, zerolog.Nop()))
+	client.err = nil
+	var noCache *TopicCache
+	_, err = noCache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	_, err = noCache.Get(context.Background(), client, 1)
+	require.NoError(t, err)
+	require.Equal(t, 7, client.queries)
+}
+
+func TestCheckTopicEnv(t *testing.T) {
+	topic := &emissionstypes.Topic{LossMethod: "mse", AllowNegative: false, EpochLength: 12, GroundTruthLag: 24}
+	env := func(vars ...string) []execute.EnvVar {
+		var envVars []execute.EnvVar
+		for i := 0; i < len(vars); i += 2 {
+			envVars = append(envVars, execute.EnvVar{Name: vars[i], Value: vars[i+1]})
+		}
+		return envVars
+	}
+
+	require.Empty(t, checkTopicEnv(topic, env("TOPIC_ID", "1")))
+	require.Empty(t, checkTopicEnv(topic, env(
+		"LOSS_FUNCTION_ALLOWS_NEGATIVE", "false", "LOSS_METHOD", "mse", "EPOCH_LENGTH", "12", "GROUND_TRUTH_LAG", "24",
+	)))
+	// Anything but true does not allow negative values.
+	require.Empty(t, checkTopicEnv(topic, env("LOSS_FUNCTION_ALLOWS_NEGATIVE", "no")))
+
+	mismatches := checkTopicEnv(topic, env("LOSS_FUNCTION_ALLOWS_NEGATIVE", "true", "LOSS_METHOD", "mse", "EPOCH_LENGTH", "x"))
+	require.Equal(t, []TopicEnvMismatch{
+		{Name: "LOSS_FUNCTION_ALLOWS_NEGATIVE", Value: "true", Chain: "false"},
+		{Name: "EPOCH_LENGTH", Value: "x", Chain: "12"},
+	}, mismatches)
+	require.Equal(t, "LOSS_FUNCTION_ALLOWS_NEGATIVE=true (chain: false)", mismatches[0].String())
+}
+
+func TestTopicEnvFallback(t *testing.T) {
+	unavailable := errors.New("chain unavailable")
+	before := testutil.ToFloat64(topicEnvFallbacks.WithLabelValues("42"))
+	require.NoError(t, topicEnvFallback(TopicEnvMismatchWarn, 42, "42", unavailable))
+	require.Equal(t, before+1, testutil.ToFloat64(topicEnvFallbacks.WithLabelValues("42")))
+
+	// Rejecting nodes only trust the chain.
+	err := topicEnvFallback(TopicEnvMismatchReject, 42, "42", unavailable)
+	require.ErrorIs(t, err, unavailable)
+	require.Equal(t, before+1, testutil.ToFloat64(topicEnvFallbacks.WithLabelValues("42")))
+}
+
+func TestWorkerTopics(t *testing.T) {
+	topics, deprecated, err := WorkerTopics([]string{"allora-topic-1-reputer", "2"}, WorkerModeReputer)
+	require.NoError(t, err)
+	require.Equal(t, []string{"allora-topic-1-reputer", "allora-topic-2-reputer"}, topics)
+	require.Equal(t, []string{"2"}, deprecated)
+
+	topics, deprecated, err = WorkerTopics(nil, WorkerModeWorker)
+	require.NoError(t, err)
+	require.Empty(t, topics)
+	require.Empty(t, deprecated)
+
+	// Topics must be for the worker mode of the node.
+	_, _, err = WorkerTopics([]string{"allora-topic-1-reputer"}, WorkerModeWorker)
+	require.ErrorContains(t, err, "reputer mode")
+	for _, name := range []string{"topic-1", "allora-topic-1", "01", "-1"} {
+		_, _, err := WorkerTopics([]string{name}, WorkerModeWorker)
+		require.Error(t, err, name)
+	}
+}
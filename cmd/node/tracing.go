@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allora-network/b7s/models/execute"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing exporters.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+const (
+	tracingServiceName     = "allora-node"
+	defaultTracingEndpoint = "localhost:4317"
+	// How long the trace context of a request is kept for the leader to pick it up.
+	requestTraceTTL = 10 * time.Minute
+)
+
+// Span attributes shared by the spans of an inference round.
+const (
+	attrRequestID = attribute.Key("allora.request_id")
+	attrTopic     = attribute.Key("allora.topic")
+	attrMode      = attribute.Key("allora.mode")
+	attrNonce     = attribute.Key("allora.nonce")
+	attrRole      = attribute.Key("allora.role")
+)
+
+var tracer = otel.Tracer("github.com/allora-network/allora-inference-base/cmd/node")
+
+// TracingConfig configures the export of traces.
+type TracingConfig struct {
+	Exporter    string  // none, otlp or stdout
+	Endpoint    string  // OTLP gRPC collector endpoint
+	Insecure    bool    // connect to the collector without TLS
+	SampleRatio float64 // ratio of new traces sampled, requests keep the decision of their parent
+}
+
+// setupTracing installs the global tracer provider and trace context propagator. The returned
+// function flushes and stops the exporter. Tracing is a no-op with the none exporter.
+func setupTracing(ctx context.Context, cfg TracingConfig, role string) (func(context.Context) error, error) {
+	// Propagate trace context even when not exporting, so that the other nodes keep one trace.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio %v is not in the 0-1 range", cfg.SampleRatio)
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case TracingExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case TracingExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected %s, %s or %s", cfg.Exporter, TracingExporterNone, TracingExporterOTLP, TracingExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(tracingServiceName),
+		attrRole.String(role),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// endSpan records the error, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// envCarrier carries trace context in the environment of an execution request, as TRACEPARENT
+// and TRACESTATE, so that it reaches the workers through the b7s execute.Request config.
+type envCarrier struct {
+	env *[]execute.EnvVar
+}
+
+func (c envCarrier) Get(key string) string {
+	name := strings.ToUpper(key)
+	for _, v := range *c.env {
+		if v.Name == name {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+func (c envCarrier) Set(key string, value string) {
+	name := strings.ToUpper(key)
+	for i, v := range *c.env {
+		if v.Name == name {
+			(*c.env)[i].Value = value
+			return
+		}
+	}
+	*c.env = append(*c.env, execute.EnvVar{Name: name, Value: value})
+}
+
+func (c envCarrier) Keys() []string {
+	keys := make([]string, len(*c.env))
+	for i, v := range *c.env {
+		keys[i] = strings.ToLower(v.Name)
+	}
+	return keys
+}
+
+// injectTraceContext adds the trace context of ctx to the environment of an execution request.
+func injectTraceContext(ctx context.Context, env *[]execute.EnvVar) {
+	otel.GetTextMapPropagator().Inject(ctx, envCarrier{env: env})
+}
+
+// extractTraceContext returns ctx with the trace context found in the environment of an execution request.
+func extractTraceContext(ctx context.Context, env []execute.EnvVar) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, envCarrier{env: &env})
+}
+
+// requestTraces remembers the trace context of the requests a worker executed, by request ID.
+// The leader only gets the request ID along with the results, so it picks up the trace from here.
+type requestTraces struct {
+	lock    sync.Mutex
+	entries map[string]requestTrace
+}
+
+type requestTrace struct {
+	span  trace.SpanContext
+	added time.Time
+}
+
+var executedRequests = &requestTraces{entries: make(map[string]requestTrace)}
+
+func (t *requestTraces) add(requestID string, span trace.SpanContext) {
+	if !span.IsValid() {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	for id, entry := range t.entries {
+		if now.Sub(entry.added) > requestTraceTTL {
+			delete(t.entries, id)
+		}
+	}
+	t.entries[requestID] = requestTrace{span: span, added: now}
+}
+
+// context returns ctx as a child of the trace of the request, if known.
+func (t *requestTraces) context(ctx context.Context, requestID string) context.Context {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	entry, ok := t.entries[requestID]
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, entry.span)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextInEnvironment(t *testing.T) {
+	_, err := setupTracing(context.Background(), TracingConfig{Exporter: TracingExporterNone}, "head")
+	require.NoError(t, err)
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "head.execute")
+	defer span.End()
+
+	env := []execute.EnvVar{{Name: "TOPIC_ID", Value: "1"}}
+	injectTraceContext(ctx, &env)
+	require.Equal(t, "TOPIC_ID", env[0].Name)
+	require.NotEmpty(t, envCarrier{env: &env}.Get("traceparent"))
+
+	extracted := trace.SpanContextFromContext(extractTraceContext(context.Background(), env))
+	require.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	require.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+	require.True(t, extracted.IsRemote())
+
+	// Injecting again replaces the previous context instead of adding to it.
+	injectTraceContext(ctx, &env)
+	require.Len(t, env, 2)
+
+	// The leader continues the trace of the request it executed.
+	executedRequests.add("request", extracted)
+	require.Equal(t, extracted.TraceID(), trace.SpanContextFromContext(executedRequests.context(context.Background(), "request")).TraceID())
+	require.False(t, trace.SpanContextFromContext(executedRequests.context(context.Background(), "other")).IsValid())
+}
+
+func TestSetupTracing(t *testing.T) {
+	_, err := setupTracing(context.Background(), TracingConfig{Exporter: "jaeger"}, "worker")
+	require.Error(t, err)
+	_, err = setupTracing(context.Background(), TracingConfig{Exporter: TracingExporterStdout, SampleRatio: 2}, "worker")
+	require.Error(t, err)
+}
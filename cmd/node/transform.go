@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog"
+)
+
+// Policies for the values a pipeline fails to transform.
+const (
+	ValueErrorFail = "fail" // the whole bundle fails
+	ValueErrorDrop = "drop" // the element of the value is left out of the bundle
+)
+
+// Built-in value transforms.
+const (
+	ValueTransformIdentity = "identity"
+	ValueTransformLog10    = "log10"
+	ValueTransformLn       = "ln"
+	ValueTransformClamp    = "clamp" // clamp:<min>:<max>
+	ValueTransformScale    = "scale" // scale:<factor>
+)
+
+// Key of the pipeline of the topics without one of their own.
+const ValuePipelineDefault = "default"
+
+// Keys of the topic metadata, when it is a JSON object, configuring the pipeline of the topic.
+const (
+	topicMetadataValueTransform   = "value_transform"
+	topicMetadataValueErrorPolicy = "value_error_policy"
+)
+
+// ValueTransform maps a loss value output by a function to the value submitted to the chain.
+type ValueTransform func(alloraMath.Dec) (alloraMath.Dec, error)
+
+// ValueTransformFactory builds a transform from the arguments of a pipeline step.
+type ValueTransformFactory func(args []alloraMath.Dec) (ValueTransform, error)
+
+var valueTransformNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var (
+	valueTransformsLock sync.RWMutex
+	valueTransforms     = map[string]ValueTransformFactory{
+		ValueTransformIdentity: noArgs(func(v alloraMath.Dec) (alloraMath.Dec, error) { return v, nil }),
+		ValueTransformLog10:    noArgs(alloraMath.Log10),
+		ValueTransformLn:       noArgs(alloraMath.Ln),
+		ValueTransformClamp:    clampTransform,
+		ValueTransformScale:    scaleTransform,
+	}
+)
+
+// RegisterValueTransform makes a custom transform available to pipelines under the name. Names are
+// lower case letters, digits and underscores, and built-in transforms cannot be replaced.
+func RegisterValueTransform(name string, factory ValueTransformFactory) error {
+	if !valueTransformNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid value transform name %q", name)
+	}
+	valueTransformsLock.Lock()
+	defer valueTransformsLock.Unlock()
+	if _, ok := valueTransforms[name]; ok {
+		return fmt.Errorf("value transform %q is already registered", name)
+	}
+	valueTransforms[name] = factory
+	return nil
+}
+
+func noArgs(transform ValueTransform) ValueTransformFactory {
+	return func(args []alloraMath.Dec) (ValueTransform, error) {
+		if len(args) != 0 {
+			return nil, errors.New("takes no arguments")
+		}
+		return transform, nil
+	}
+}
+
+func clampTransform(args []alloraMath.Dec) (ValueTransform, error) {
+	if len(args) != 2 {
+		return nil, errors.New("expected clamp:<min>:<max>")
+	}
+	lower, upper := args[0], args[1]
+	if lower.Gt(upper) {
+		return nil, fmt.Errorf("min %s is greater than max %s", lower, upper)
+	}
+	return func(v alloraMath.Dec) (alloraMath.Dec, error) {
+		if v.Lt(lower) {
+			return lower, nil
+		}
+		if v.Gt(upper) {
+			return upper, nil
+		}
+		return v, nil
+	}, nil
+}
+
+func scaleTransform(args []alloraMath.Dec) (ValueTransform, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected scale:<factor>")
+	}
+	factor := args[0]
+	return func(v alloraMath.Dec) (alloraMath.Dec, error) {
+		return v.Mul(factor)
+	}, nil
+}
+
+// ValuePipeline transforms the loss values of a topic, the forecasted losses of workers and the
+// losses of reputers, by applying its steps in order.
+type ValuePipeline struct {
+	Spec    string
+	OnError string
+	steps   []ValueTransform
+}
+
+// ParseValuePipeline parses steps of the form <transform>[:<arg>...] separated by |, e.g.
+// clamp:0.0001:1000000|log10. An empty spec is the identity. The error policy is fail if empty.
+func ParseValuePipeline(spec string, onError string) (*ValuePipeline, error) {
+	switch onError {
+	case "":
+		onError = ValueErrorFail
+	case ValueErrorFail, ValueErrorDrop:
+	default:
+		return nil, fmt.Errorf("invalid value error policy %q, expected %s or %s", onError, ValueErrorFail, ValueErrorDrop)
+	}
+	pipeline := &ValuePipeline{Spec: spec, OnError: onError}
+	if spec == "" {
+		return pipeline, nil
+	}
+
+	valueTransformsLock.RLock()
+	defer valueTransformsLock.RUnlock()
+	for _, step := range strings.Split(spec, "|") {
+		parts := strings.Split(step, ":")
+		factory, ok := valueTransforms[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown value transform %q in %q", parts[0], spec)
+		}
+		args := make([]alloraMath.Dec, len(parts)-1)
+		for i, arg := range parts[1:] {
+			dec, err := alloraMath.NewDecFromString(arg)
+			if err != nil || dec.IsNaN() {
+				return nil, fmt.Errorf("invalid argument %q of value transform %s", arg, step)
+			}
+			args[i] = dec
+		}
+		transform, err := factory(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value transform %s: %w", step, err)
+		}
+		pipeline.steps = append(pipeline.steps, transform)
+	}
+	return pipeline, nil
+}
+
+// Apply transforms the value.
+func (p *ValuePipeline) Apply(v alloraMath.Dec) (alloraMath.Dec, error) {
+	for _, step := range p.steps {
+		var err error
+		v, err = step(v)
+		if err != nil {
+			return alloraMath.Dec{}, err
+		}
+	}
+	return v, nil
+}
+
+// WorkerValue is a parsed and transformed value attributed to a worker.
+type WorkerValue struct {
+	Worker string
+	Value  alloraMath.Dec
+}
+
+// ApplyAll parses and transforms the values of the workers. A value failing to transform fails them
+// all or, with the drop policy, is left out and counted in the returned number of dropped values.
+func (p *ValuePipeline) ApplyAll(values []NodeValue, log zerolog.Logger) ([]WorkerValue, int, error) {
+	dropped := 0
+	out := make([]WorkerValue, 0, len(values))
+	for _, v := range values {
+		dec, err := alloraMath.NewDecFromString(string(v.Value))
+		if err != nil {
+			return nil, dropped, fmt.Errorf("invalid value %q of worker %s: %w", v.Value, v.Worker, err)
+		}
+		dec, err = p.Apply(dec)
+		if err != nil {
+			if p.OnError == ValueErrorDrop {
+				log.Warn().Err(err).Str("worker", v.Worker).Str("value", string(v.Value)).Str("pipeline", p.Spec).Msg("dropped value failing to transform")
+				dropped++
+				continue
+			}
+			return nil, dropped, fmt.Errorf("could not transform value %s of worker %s with %q: %w", v.Value, v.Worker, p.Spec, err)
+		}
+		out = append(out, WorkerValue{Worker: v.Worker, Value: dec})
+	}
+	return out, dropped, nil
+}
+
+// ValuePipelines are the value pipelines configured on the node, by topic ID.
+type ValuePipelines struct {
+	topics map[string]*ValuePipeline // by topic ID, or ValuePipelineDefault
+	// Error policy of the pipelines without one of their own.
+	onError string
+}
+
+// ParseValuePipelines parses <topic id>=<pipeline> and <topic id>=<error policy> pairs, default
+// standing for the topics without one of their own.
+func ParseValuePipelines(transformSpecs []string, policySpecs []string) (ValuePipelines, error) {
+	specs := map[string]string{}
+	for _, spec := range transformSpecs {
+		topic, pipeline, err := parseValuePipelineKey(spec)
+		if err != nil {
+			return ValuePipelines{}, fmt.Errorf("invalid value transform %q: %w", spec, err)
+		}
+		specs[topic] = pipeline
+	}
+	policies := map[string]string{}
+	for _, spec := range policySpecs {
+		topic, policy, err := parseValuePipelineKey(spec)
+		if err != nil {
+			return ValuePipelines{}, fmt.Errorf("invalid value error policy %q: %w", spec, err)
+		}
+		if _, ok := specs[topic]; !ok && topic != ValuePipelineDefault {
+			return ValuePipelines{}, fmt.Errorf("value error policy %q given without a value transform for topic %s", spec, topic)
+		}
+		policies[topic] = policy
+	}
+
+	// Check the default policy even if no pipeline uses it.
+	pipelines := ValuePipelines{topics: map[string]*ValuePipeline{}, onError: policies[ValuePipelineDefault]}
+	if _, err := ParseValuePipeline("", pipelines.onError); err != nil {
+		return ValuePipelines{}, err
+	}
+	for topic, spec := range specs {
+		policy, ok := policies[topic]
+		if !ok {
+			policy = pipelines.onError
+		}
+		pipeline, err := ParseValuePipeline(spec, policy)
+		if err != nil {
+			return ValuePipelines{}, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		pipelines.topics[topic] = pipeline
+	}
+	return pipelines, nil
+}
+
+func parseValuePipelineKey(spec string) (string, string, error) {
+	topic, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return "", "", errors.New("expected <topic id>=<value> or default=<value>")
+	}
+	if topic != ValuePipelineDefault {
+		if _, err := strconv.ParseUint(topic, 10, 64); err != nil {
+			return "", "", fmt.Errorf("invalid topic ID %q", topic)
+		}
+	}
+	return topic, value, nil
+}
+
+// For returns the pipeline of the topic: the one configured for it, then the one given by the topic
+// metadata on chain, if any, then the default one. Without any, loss values are log10 transformed
+// unless the topic allows negative values.
+func (p ValuePipelines) For(topicId uint64, topic *emissionstypes.Topic, allowsNegative bool) (*ValuePipeline, error) {
+	if pipeline, ok := p.topics[strconv.FormatUint(topicId, 10)]; ok {
+		return pipeline, nil
+	}
+	if topic != nil {
+		pipeline, ok, err := topicMetadataPipeline(topic.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("topic %d: %w", topicId, err)
+		}
+		if ok {
+			return pipeline, nil
+		}
+	}
+	if pipeline, ok := p.topics[ValuePipelineDefault]; ok {
+		return pipeline, nil
+	}
+	if allowsNegative {
+		return ParseValuePipeline(ValueTransformIdentity, p.onError)
+	}
+	return ParseValuePipeline(ValueTransformLog10, p.onError)
+}
+
+// topicMetadataPipeline returns the pipeline set in the metadata of a topic, if it is a JSON object
+// with a value_transform.
+func topicMetadataPipeline(metadata string) (*ValuePipeline, bool, error) {
+	var fields map[string]any
+	if json.Unmarshal([]byte(metadata), &fields) != nil {
+		return nil, false, nil
+	}
+	spec, ok := fields[topicMetadataValueTransform].(string)
+	if !ok {
+		return nil, false, nil
+	}
+	policy, _ := fields[topicMetadataValueErrorPolicy].(string)
+	pipeline, err := ParseValuePipeline(spec, policy)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid value transform in topic metadata: %w", err)
+	}
+	return pipeline, true, nil
+}
+
+// transformValues transforms the values of the workers with the pipeline, counting those dropped.
+func transformValues(pipeline *ValuePipeline, values []NodeValue, topicLabel string, mode string, log zerolog.Logger) ([]WorkerValue, error) {
+	out, dropped, err := pipeline.ApplyAll(values, log)
+	if dropped > 0 {
+		valuesDropped.WithLabelValues(topicLabel, mode).Add(float64(dropped))
+	}
+	return out, err
+}
+
+// transformValue parses and transforms a value not attributed to a worker, which cannot be dropped.
+func transformValue(pipeline *ValuePipeline, value DecimalValue) (alloraMath.Dec, error) {
+	dec, err := alloraMath.NewDecFromString(string(value))
+	if err != nil {
+		return alloraMath.Dec{}, fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	dec, err = pipeline.Apply(dec)
+	if err != nil {
+		return alloraMath.Dec{}, fmt.Errorf("could not transform value %s with %q: %w", value, pipeline.Spec, err)
+	}
+	return dec, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func requireDecEqual(t *testing.T, want string, got alloraMath.Dec) {
+	t.Helper()
+	require.True(t, alloraMath.MustNewDecFromString(want).Equal(got), "want %s, got %s", want, got)
+}
+
+func TestParseValuePipeline(t *testing.T) {
+	pipeline, err := ParseValuePipeline("clamp:0.001:1000|log10", "")
+	require.NoError(t, err)
+	require.Equal(t, ValueErrorFail, pipeline.OnError)
+	for in, want := range map[string]string{"100": "2", "0.000001": "-3", "1000000": "3"} {
+		out, err := pipeline.Apply(alloraMath.MustNewDecFromString(in))
+		require.NoError(t, err, in)
+		requireDecEqual(t, want, out)
+	}
+
+	pipeline, err = ParseValuePipeline("scale:-2|identity", ValueErrorDrop)
+	require.NoError(t, err)
+	out, err := pipeline.Apply(alloraMath.MustNewDecFromString("1.5"))
+	require.NoError(t, err)
+	requireDecEqual(t, "-3", out)
+
+	// The empty pipeline is the identity.
+	pipeline, err = ParseValuePipeline("", "")
+	require.NoError(t, err)
+	out, err = pipeline.Apply(alloraMath.MustNewDecFromString("-7"))
+	require.NoError(t, err)
+	requireDecEqual(t, "-7", out)
+
+	for _, spec := range []string{"log2", "log10:1", "clamp:1", "clamp:2:1", "clamp:a:b", "scale", "log10|", "|log10", "LOG10"} {
+		_, err := ParseValuePipeline(spec, "")
+		require.Error(t, err, spec)
+	}
+	_, err = ParseValuePipeline("log10", "skip")
+	require.Error(t, err)
+}
+
+func TestValuePipelineApplyAll(t *testing.T) {
+	values := []NodeValue{{Worker: "a", Value: "10"}, {Worker: "b", Value: "-1"}, {Worker: "c", Value: "0.1"}}
+
+	pipeline, err := ParseValuePipeline(ValueTransformLog10, ValueErrorFail)
+	require.NoError(t, err)
+	_, _, err = pipeline.ApplyAll(values, zerolog.Nop())
+	require.ErrorContains(t, err, "worker b")
+
+	pipeline, err = ParseValuePipeline(ValueTransformLog10, ValueErrorDrop)
+	require.NoError(t, err)
+	out, dropped, err := pipeline.ApplyAll(values, zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+	require.Len(t, out, 2)
+	require.Equal(t, "a", out[0].Worker)
+	requireDecEqual(t, "1", out[0].Value)
+	require.Equal(t, "c", out[1].Worker)
+	requireDecEqual(t, "-1", out[1].Value)
+
+	// Values which are not numbers are never dropped.
+	_, _, err = pipeline.ApplyAll([]NodeValue{{Worker: "a", Value: "x"}}, zerolog.Nop())
+	require.Error(t, err)
+}
+
+func TestRegisterValueTransform(t *testing.T) {
+	require.NoError(t, RegisterValueTransform("test_negate", noArgs(func(v alloraMath.Dec) (alloraMath.Dec, error) {
+		return v.Mul(alloraMath.MustNewDecFromString("-1"))
+	})))
+	require.Error(t, RegisterValueTransform("test_negate", clampTransform))
+	require.Error(t, RegisterValueTransform(ValueTransformLog10, clampTransform))
+	require.Error(t, RegisterValueTransform("Bad-Name", clampTransform))
+
+	pipeline, err := ParseValuePipeline("test_negate|scale:2", "")
+	require.NoError(t, err)
+	out, err := pipeline.Apply(alloraMath.MustNewDecFromString("3"))
+	require.NoError(t, err)
+	requireDecEqual(t, "-6", out)
+}
+
+func TestValuePipelinesFor(t *testing.T) {
+	pipelines, err := ParseValuePipelines(nil, nil)
+	require.NoError(t, err)
+	// Without configuration, values are log10 transformed unless negative values are allowed.
+	pipeline, err := pipelines.For(1, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, ValueTransformLog10, pipeline.Spec)
+	pipeline, err = pipelines.For(1, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, ValueTransformIdentity, pipeline.Spec)
+
+	pipelines, err = ParseValuePipelines(
+		[]string{"1=clamp:0:1|ln", "default=scale:10"},
+		[]string{"1=drop"},
+	)
+	require.NoError(t, err)
+	metadata := &emissionstypes.Topic{Metadata: `{"value_transform": "identity", "value_error_policy": "drop"}`}
+
+	// The topic configuration comes first, then the topic metadata, then the default configuration.
+	pipeline, err = pipelines.For(1, metadata, false)
+	require.NoError(t, err)
+	require.Equal(t, "clamp:0:1|ln", pipeline.Spec)
+	require.Equal(t, ValueErrorDrop, pipeline.OnError)
+	pipeline, err = pipelines.For(2, metadata, false)
+	require.NoError(t, err)
+	require.Equal(t, ValueTransformIdentity, pipeline.Spec)
+	require.Equal(t, ValueErrorDrop, pipeline.OnError)
+	pipeline, err = pipelines.For(2, &emissionstypes.Topic{Metadata: "ETH 24h prediction"}, false)
+	require.NoError(t, err)
+	require.Equal(t, "scale:10", pipeline.Spec)
+	require.Equal(t, ValueErrorFail, pipeline.OnError)
+
+	_, err = pipelines.For(2, &emissionstypes.Topic{Metadata: `{"value_transform": "log2"}`}, false)
+	require.Error(t, err)
+
+	for _, specs := range [][2][]string{
+		{{"log10"}, nil},
+		{{"x=log10"}, nil},
+		{{"1=log2"}, nil},
+		{nil, {"1=drop"}},
+		{{"1=log10"}, {"1=skip"}},
+		{nil, {"default=skip"}},
+	} {
+		_, err := ParseValuePipelines(specs[0], specs[1])
+		require.Error(t, err, specs)
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
+	"time"
+
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 	"github.com/allora-network/b7s/config"
 	"github.com/allora-network/b7s/models/blockless"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
@@ -12,25 +16,49 @@ import (
 
 type alloraCfg struct {
 	config.Config
+	LogFormat      string            // console or json
+	LogLevels      map[string]string // log level per subsystem, overriding the log level
 	AppChainConfig AppChainConfig
+	MetricsConfig  MetricsConfig
+	TracingConfig  TracingConfig
+	HistoryConfig  HistoryConfig
+	APIAuth        APIAuthSettings
+	Admission      AdmissionConfig
+
+	GRPCAPI           string        // address of the head gRPC API, disabled if empty
+	AsyncJobRetention time.Duration // how long finished async executions can be polled
+	AsyncCallbackNets []string      // networks async callbacks can be posted to besides public addresses
+	BatchConcurrency  int           // requests of a batch executed at once
+	BatchMaxSize      int           // requests allowed in a batch
+	MaxRequestBody    int64         // max size of REST API request bodies, in bytes
+
+	// Sources of the account secrets, cleared once loaded into the AppChainConfig.
+	RestoreMnemonic       string
+	RestoreMnemonicFile   string
+	AccountPassphrase     string
+	AccountPassphraseFile string
 }
 
 type AppChain struct {
 	Address              string
 	Account              cosmosaccount.Account
-	Client               *cosmosclient.Client
+	Client               *cosmosclient.Client // client of the preferred endpoint, used for signing
+	Endpoints            *EndpointPool
 	EmissionsQueryClient emissionstypes.QueryClient
 	BankQueryClient      banktypes.QueryClient
 	Config               AppChainConfig
 	Logger               zerolog.Logger
+
+	// broadcast replaces BroadcastTx for testing.
+	broadcast func(ctx context.Context, msgs ...sdktypes.Msg) (cosmosclient.Response, error)
 }
 
 type AppChainConfig struct {
-	NodeRPCAddress           string // rpc node to attach to
-	AddressPrefix            string // prefix for the allora addresses
-	AddressKeyName           string // load a address by key from the keystore
-	AddressRestoreMnemonic   string
-	AddressAccountPassphrase string
+	NodeRPCAddresses         []string // rpc nodes to attach to, in order of preference until latencies are known
+	AddressPrefix            string   // prefix for the allora addresses
+	AddressKeyName           string   // load a address by key from the keystore
+	AddressRestoreMnemonic   Secret   // wiped once the account is imported into the keyring
+	AddressAccountPassphrase Secret
 	AlloraHomeDir            string // home directory for the allora keystore
 	StringSeperator          string // string seperator used for key identifiers in allora
 	LibP2PKey                string // the libp2p key used to sign offchain communications
@@ -38,22 +66,40 @@ type AppChainConfig struct {
 	MultiAddress             string
 	TopicIds                 []string
 	NodeRole                 blockless.NodeRole
-	ReconnectSeconds         uint64  // seconds to wait for reconnection
+	ReconnectSeconds         uint64  // max seconds between reconnection attempts and connection checks
 	InitialStake             int64   // uallo to initially stake upon registration on a new topi
 	WorkerMode               string  // Allora Network worker mode to use
-	Gas                      string  // gas to use for the allora client
-	GasAdjustment            float64 // gas adjustment to use for the allora client
+	Gas                      string  // gas to use for the allora client, the default strategy unless one is given
+	GasAdjustment            float64 // gas adjustment to use for the allora client, the default multiplier unless a strategy is given
+	GasStrategySpecs         []string
+	GasStrategies            GasStrategies // gas strategy per msg type
+	ValueTransformSpecs      []string
+	ValueErrorPolicySpecs    []string
+	ValuePipelines           ValuePipelines // loss value transforms per topic
+	TopicCacheTTL            time.Duration  // how long topic definitions fetched from chain are kept
+	TopicCache               *TopicCache    // topic definitions, shared across reconnections
+	TopicEnvMismatch         string         // what to do with executions whose environment disagrees with the topic on chain
+	GasPricesSpec            string
+	GasPrices                sdktypes.DecCoins // fees paid per unit of gas
+	MaxTxBytes               int               // bulk payloads are trimmed to txs of at most this size
+	MaxTxGas                 uint64            // bulk payloads are trimmed to txs needing at most this gas, 0 for no limit
+	FeeBudgetLimit           string
+	FeeBudgetWindow          string
+	FeeBudget                *FeeBudget // fees the account may spend per window, shared across reconnections
+	DryRun                   bool       // build, sign and simulate payloads but never broadcast them
+	DryRunOutput             string     // file path or URL the dry run payloads are written to
+	DryRunSink               PayloadSink
 }
 
 type NodeValue struct {
-	Worker string `json:"worker,omitempty"`
-	Value  string `json:"value,omitempty"`
+	Worker string       `json:"worker,omitempty"`
+	Value  DecimalValue `json:"value,omitempty"`
 }
 
 // WORKER
 type InferenceForecastResponse struct {
-	InfererValue     string      `json:"infererValue,omitempty"`
-	ForecasterValues []NodeValue `json:"forecasterValue,omitempty"`
+	InfererValue     DecimalValue `json:"infererValue,omitempty"`
+	ForecasterValues []NodeValue  `json:"forecasterValue,omitempty"`
 }
 
 type WorkerDataResponse struct {
@@ -65,13 +111,13 @@ type WorkerDataResponse struct {
 // REPUTER
 // Local struct to hold the value bundle from the wasm function response
 type ValueBundle struct {
-	CombinedValue          string      `json:"combinedValue,omitempty"`
-	NaiveValue             string      `json:"naiveValue,omitempty"`
-	InfererValues          []NodeValue `json:"infererValues,omitempty"`
-	ForecasterValues       []NodeValue `json:"forecasterValues,omitempty"`
-	OneOutInfererValues    []NodeValue `json:"oneOutInfererValues,omitempty"`
-	OneOutForecasterValues []NodeValue `json:"oneOutForecasterValues,omitempty"`
-	OneInForecasterValues  []NodeValue `json:"oneInForecasterValues,omitempty"`
+	CombinedValue          DecimalValue `json:"combinedValue,omitempty"`
+	NaiveValue             DecimalValue `json:"naiveValue,omitempty"`
+	InfererValues          []NodeValue  `json:"infererValues,omitempty"`
+	ForecasterValues       []NodeValue  `json:"forecasterValues,omitempty"`
+	OneOutInfererValues    []NodeValue  `json:"oneOutInfererValues,omitempty"`
+	OneOutForecasterValues []NodeValue  `json:"oneOutForecasterValues,omitempty"`
+	OneInForecasterValues  []NodeValue  `json:"oneInForecasterValues,omitempty"`
 }
 
 // Wrapper around the ReputerValueBundle to include the block height and topic id for the leader
@@ -93,7 +139,40 @@ const (
 
 type AlloraExecutor struct {
 	blockless.Executor
-	appChain *AppChain
+	chain *ChainConnection
+	log   zerolog.Logger
 }
 
 const AlloraExponential = 18
+
+// MetricsConfig configures the server exposing metrics, health and optionally pprof.
+type MetricsConfig struct {
+	Address           string // empty to disable the server
+	BasicAuthUser     string
+	BasicAuthPassword Secret
+	BearerToken       Secret
+	TLSCertFile       string
+	TLSKeyFile        string
+	Pprof             bool
+	Topics            []string // topic IDs head metrics are labelled with, besides the configured ones
+
+	// Sources of the secrets, read into the config at startup.
+	BasicAuthPasswordFile string
+	BearerTokenFile       string
+}
+
+// APIAuthSettings configures the authentication of the head REST API clients.
+type APIAuthSettings struct {
+	ClientsFile  string // JSON file of the API clients, empty to disable authentication
+	TLSCertFile  string
+	TLSKeyFile   string
+	ClientCAFile string // CA of the client certificates, enables mTLS
+}
+
+// HistoryConfig configures the execution history of the head.
+type HistoryConfig struct {
+	DatabasePath      string
+	Size              int // records kept, 0 disables the history
+	TrackSubmissions  bool
+	SubmissionTimeout time.Duration
+}
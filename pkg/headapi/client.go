@@ -0,0 +1,193 @@
+package headapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allora-network/b7s/models/execute"
+)
+
+// Headers authenticating the requests of API clients. Signed requests carry the client name, the
+// Unix time of the request, a nonce unique to the request and the signature computed by SignRequest.
+const (
+	HeaderAPIKey    = "X-API-Key"
+	HeaderClient    = "X-Allora-Client"
+	HeaderTimestamp = "X-Allora-Timestamp"
+	HeaderNonce     = "X-Allora-Nonce"
+	HeaderSignature = "X-Allora-Signature"
+)
+
+// ErrNotFound is returned by Client.Result for requests without result.
+var ErrNotFound = errors.New("not found")
+
+// SignRequest returns the HMAC signature of a request to the head REST API: the hex encoded
+// HMAC-SHA256, keyed with the client secret, of the method, the request URI, the timestamp, the
+// nonce and the hex encoded SHA-256 of the body, joined by newlines.
+func SignRequest(secret string, method string, requestURI string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewNonce returns a random nonce for a signed request.
+func NewNonce() string {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	return hex.EncodeToString(nonce)
+}
+
+// StatusError is an error response of the head.
+type StatusError struct {
+	StatusCode int
+	// Error code, such as ErrorBadRequest, if the head gave one.
+	Code    string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("head responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("head responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the REST API of a head node.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	hmacClient string
+	hmacSecret string
+	now        func() time.Time
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests through c, e.g. to present a TLS client certificate.
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithAPIKey authenticates the requests with an API key.
+func WithAPIKey(key string) Option {
+	return func(client *Client) {
+		client.apiKey = key
+	}
+}
+
+// WithHMAC signs the requests as the named client.
+func WithHMAC(name string, secret string) Option {
+	return func(client *Client) {
+		client.hmacClient = name
+		client.hmacSecret = secret
+	}
+}
+
+// NewClient creates a client of the head listening at baseURL, e.g. http://localhost:6000.
+func NewClient(baseURL string, opts ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// Execute executes a function on the workers of the request topic and returns the aggregated
+// results. Failed executions are reported by the error of the response, not as errors.
+func (c *Client) Execute(ctx context.Context, req ExecuteRequest) (ExecuteResponse, error) {
+	var res ExecuteResponse
+	err := c.post(ctx, PathExecute, req, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && res.Error != nil {
+		return res, nil
+	}
+	return res, err
+}
+
+// Install installs a function on the workers.
+func (c *Client) Install(ctx context.Context, req InstallRequest) (InstallResponse, error) {
+	var res InstallResponse
+	err := c.post(ctx, PathInstall, req, &res)
+	return res, err
+}
+
+// Result returns the result of an execution by its request ID, ErrNotFound if the head has none.
+func (c *Client) Result(ctx context.Context, requestID string) (execute.Result, error) {
+	var res execute.Result
+	err := c.post(ctx, PathResult, ResultRequest{ID: requestID}, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return res, ErrNotFound
+	}
+	return res, err
+}
+
+func (c *Client) post(ctx context.Context, path string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.authenticate(req, body)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var errRes ErrorResponse
+		_ = json.Unmarshal(resBody, &errRes)
+		// Failed executions come with their response.
+		_ = json.Unmarshal(resBody, out)
+		return &StatusError{StatusCode: res.StatusCode, Code: errRes.Code, Message: errRes.Message}
+	}
+	err = json.Unmarshal(resBody, out)
+	if err != nil {
+		return fmt.Errorf("could not decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) authenticate(req *http.Request, body []byte) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.hmacSecret != "" {
+		timestamp := strconv.FormatInt(c.now().Unix(), 10)
+		nonce := NewNonce()
+		req.Header.Set(HeaderClient, c.hmacClient)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderNonce, nonce)
+		req.Header.Set(HeaderSignature, SignRequest(c.hmacSecret, req.Method, req.URL.RequestURI(), timestamp, nonce, body))
+	}
+}
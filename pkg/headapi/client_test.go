@@ -0,0 +1,73 @@
+package headapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "signer", r.Header.Get(HeaderClient))
+		require.Equal(t, strconv.FormatInt(now.Unix(), 10), r.Header.Get(HeaderTimestamp))
+		require.Len(t, r.Header.Get(HeaderNonce), 32)
+		require.Equal(t, SignRequest("secret", r.Method, r.URL.RequestURI(), r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderNonce), body), r.Header.Get(HeaderSignature))
+		require.NoError(t, ValidateRequest(r.Method, r.URL.Path, body))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case PathExecute:
+			var req ExecuteRequest
+			require.NoError(t, json.Unmarshal(body, &req))
+			if req.Topic == "2" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_ = json.NewEncoder(w).Encode(ExecuteResponse{Code: codes.Timeout, Error: &ExecutionError{Code: ErrorNoWorkers, Message: "no worker"}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(ExecuteResponse{Code: codes.OK, RequestID: "request-" + req.Topic})
+		case PathInstall:
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Message: "client signer lacks the install permission"})
+		case PathResult:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", WithHMAC("signer", "secret"))
+	client.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	req := ExecuteRequest{Request: execute.Request{FunctionID: "bafy", Method: "main.wasm"}, Topic: "1"}
+	res, err := client.Execute(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, codes.OK, res.Code)
+	require.Equal(t, "request-1", res.RequestID)
+
+	// Failed executions are not errors.
+	req.Topic = "2"
+	res, err = client.Execute(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, ErrorNoWorkers, res.Error.Code)
+
+	_, err = client.Install(ctx, InstallRequest{CID: "bafy"})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+	require.Equal(t, "client signer lacks the install permission", statusErr.Message)
+
+	_, err = client.Result(ctx, "request-1")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: allora/head/v1/head.proto
+
+package headv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	HeadService_Execute_FullMethodName         = "/allora.head.v1.HeadService/Execute"
+	HeadService_ExecuteStream_FullMethodName   = "/allora.head.v1.HeadService/ExecuteStream"
+	HeadService_Install_FullMethodName         = "/allora.head.v1.HeadService/Install"
+	HeadService_ExecutionResult_FullMethodName = "/allora.head.v1.HeadService/ExecutionResult"
+	HeadService_Health_FullMethodName          = "/allora.head.v1.HeadService/Health"
+)
+
+// HeadServiceClient is the client API for HeadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HeadServiceClient interface {
+	// Execute executes a function on the workers of a topic and waits for the aggregated results.
+	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	// ExecuteStream executes a function and streams its progress, from the roll call to the
+	// submission of the results to the chain. The last message has done set.
+	ExecuteStream(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (HeadService_ExecuteStreamClient, error)
+	// Install installs a function on the workers.
+	Install(ctx context.Context, in *InstallRequest, opts ...grpc.CallOption) (*InstallResponse, error)
+	// ExecutionResult returns the result of an execution by its request ID.
+	ExecutionResult(ctx context.Context, in *ExecutionResultRequest, opts ...grpc.CallOption) (*ExecutionResultResponse, error)
+	// Health checks that the head is up.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type headServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHeadServiceClient(cc grpc.ClientConnInterface) HeadServiceClient {
+	return &headServiceClient{cc}
+}
+
+func (c *headServiceClient) Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error) {
+	out := new(ExecuteResponse)
+	err := c.cc.Invoke(ctx, HeadService_Execute_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headServiceClient) ExecuteStream(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (HeadService_ExecuteStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HeadService_ServiceDesc.Streams[0], HeadService_ExecuteStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &headServiceExecuteStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type HeadService_ExecuteStreamClient interface {
+	Recv() (*ExecuteProgress, error)
+	grpc.ClientStream
+}
+
+type headServiceExecuteStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *headServiceExecuteStreamClient) Recv() (*ExecuteProgress, error) {
+	m := new(ExecuteProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *headServiceClient) Install(ctx context.Context, in *InstallRequest, opts ...grpc.CallOption) (*InstallResponse, error) {
+	out := new(InstallResponse)
+	err := c.cc.Invoke(ctx, HeadService_Install_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headServiceClient) ExecutionResult(ctx context.Context, in *ExecutionResultRequest, opts ...grpc.CallOption) (*ExecutionResultResponse, error) {
+	out := new(ExecutionResultResponse)
+	err := c.cc.Invoke(ctx, HeadService_ExecutionResult_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, HeadService_Health_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HeadServiceServer is the server API for HeadService service.
+// All implementations must embed UnimplementedHeadServiceServer
+// for forward compatibility
+type HeadServiceServer interface {
+	// Execute executes a function on the workers of a topic and waits for the aggregated results.
+	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	// ExecuteStream executes a function and streams its progress, from the roll call to the
+	// submission of the results to the chain. The last message has done set.
+	ExecuteStream(*ExecuteRequest, HeadService_ExecuteStreamServer) error
+	// Install installs a function on the workers.
+	Install(context.Context, *InstallRequest) (*InstallResponse, error)
+	// ExecutionResult returns the result of an execution by its request ID.
+	ExecutionResult(context.Context, *ExecutionResultRequest) (*ExecutionResultResponse, error)
+	// Health checks that the head is up.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedHeadServiceServer()
+}
+
+// UnimplementedHeadServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedHeadServiceServer struct {
+}
+
+func (UnimplementedHeadServiceServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+func (UnimplementedHeadServiceServer) ExecuteStream(*ExecuteRequest, HeadService_ExecuteStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteStream not implemented")
+}
+func (UnimplementedHeadServiceServer) Install(context.Context, *InstallRequest) (*InstallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Install not implemented")
+}
+func (UnimplementedHeadServiceServer) ExecutionResult(context.Context, *ExecutionResultRequest) (*ExecutionResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecutionResult not implemented")
+}
+func (UnimplementedHeadServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedHeadServiceServer) mustEmbedUnimplementedHeadServiceServer() {}
+
+// UnsafeHeadServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HeadServiceServer will
+// result in compilation errors.
+type UnsafeHeadServiceServer interface {
+	mustEmbedUnimplementedHeadServiceServer()
+}
+
+func RegisterHeadServiceServer(s grpc.ServiceRegistrar, srv HeadServiceServer) {
+	s.RegisterService(&HeadService_ServiceDesc, srv)
+}
+
+func _HeadService_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadServiceServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadService_Execute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadServiceServer).Execute(ctx, req.(*ExecuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadService_ExecuteStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecuteRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HeadServiceServer).ExecuteStream(m, &headServiceExecuteStreamServer{stream})
+}
+
+type HeadService_ExecuteStreamServer interface {
+	Send(*ExecuteProgress) error
+	grpc.ServerStream
+}
+
+type headServiceExecuteStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *headServiceExecuteStreamServer) Send(m *ExecuteProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _HeadService_Install_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadServiceServer).Install(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadService_Install_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadServiceServer).Install(ctx, req.(*InstallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadService_ExecutionResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecutionResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadServiceServer).ExecutionResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadService_ExecutionResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadServiceServer).ExecutionResult(ctx, req.(*ExecutionResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HeadService_ServiceDesc is the grpc.ServiceDesc for HeadService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HeadService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "allora.head.v1.HeadService",
+	HandlerType: (*HeadServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Execute",
+			Handler:    _HeadService_Execute_Handler,
+		},
+		{
+			MethodName: "Install",
+			Handler:    _HeadService_Install_Handler,
+		},
+		{
+			MethodName: "ExecutionResult",
+			Handler:    _HeadService_ExecutionResult_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _HeadService_Health_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteStream",
+			Handler:       _HeadService_ExecuteStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "allora/head/v1/head.proto",
+}
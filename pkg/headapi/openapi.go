@@ -0,0 +1,235 @@
+package headapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+// Spec returns the OpenAPI 3 specification of the head REST API, as JSON.
+func Spec() []byte {
+	return specJSON
+}
+
+var loadSpec = sync.OnceValues(func() (map[string]any, error) {
+	var spec map[string]any
+	err := json.Unmarshal(specJSON, &spec)
+	return spec, err
+})
+
+// ValidationError is a request body not matching the schema of its operation.
+type ValidationError struct {
+	Field   string // path of the invalid field in the body, empty for the body itself
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return "request body " + e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// ValidateRequest checks the JSON body of a request to the operation at the path, as written in
+// the spec (e.g. /api/v1/executions/{id}), against the operation's request body schema. Bodies of
+// operations without schema are not checked.
+func ValidateRequest(method string, path string, body []byte) error {
+	spec, err := loadSpec()
+	if err != nil {
+		return fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+
+	operation, _ := lookup(spec, "paths", path, strings.ToLower(method)).(map[string]any)
+	requestBody, _ := operation["requestBody"].(map[string]any)
+	schema, _ := lookup(requestBody, "content", "application/json", "schema").(map[string]any)
+	if schema == nil {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required, _ := requestBody["required"].(bool); required {
+			return &ValidationError{Message: "is required"}
+		}
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	err = decoder.Decode(&value)
+	if err != nil {
+		return &ValidationError{Message: "is not valid JSON: " + err.Error()}
+	}
+	return validateValue(spec, value, schema, "")
+}
+
+func lookup(node any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+// validateValue checks a value against the subset of JSON schema used by the spec.
+func validateValue(spec map[string]any, value any, schema map[string]any, field string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name, found := strings.CutPrefix(ref, "#/components/schemas/")
+		target, _ := lookup(spec, "components", "schemas", name).(map[string]any)
+		if !found || target == nil {
+			return fmt.Errorf("unresolved schema reference %s", ref)
+		}
+		return validateValue(spec, value, target, field)
+	}
+	// Unset optional fields.
+	if value == nil {
+		return nil
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, s := range all {
+			sub, _ := s.(map[string]any)
+			if err := validateValue(spec, value, sub, field); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		var firstErr error
+		for _, s := range anyOf {
+			sub, _ := s.(map[string]any)
+			err := validateValue(spec, value, sub, field)
+			if err == nil {
+				firstErr = nil
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+
+	kind, _ := schema["type"].(string)
+	switch v := value.(type) {
+	case map[string]any:
+		if kind != "" && kind != "object" {
+			return invalidType(field, kind)
+		}
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, ok := v[name]; !ok {
+					return &ValidationError{Field: joinField(field, name), Message: "is required"}
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, propValue := range v {
+			propSchema, ok := properties[name].(map[string]any)
+			if !ok {
+				continue
+			}
+			if err := validateValue(spec, propValue, propSchema, joinField(field, name)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if kind != "" && kind != "array" {
+			return invalidType(field, kind)
+		}
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(v)) < minItems {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must have at least %d items", int(minItems))}
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			if items == nil {
+				break
+			}
+			if err := validateValue(spec, item, items, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case string:
+		if kind != "" && kind != "string" {
+			return invalidType(field, kind)
+		}
+		if minLength, ok := schema["minLength"].(float64); ok && float64(utf8.RuneCountInString(v)) < minLength {
+			if minLength == 1 {
+				return &ValidationError{Field: field, Message: "must not be empty"}
+			}
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be at least %d characters long", int(minLength))}
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			matched, err := regexp.MatchString(pattern, v)
+			if err != nil {
+				return fmt.Errorf("invalid pattern of %s: %w", field, err)
+			}
+			if !matched {
+				return &ValidationError{Field: field, Message: fmt.Sprintf("must match %s", pattern)}
+			}
+		}
+		if enum, ok := schema["enum"].([]any); ok {
+			for _, e := range enum {
+				if e == v {
+					return nil
+				}
+			}
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be one of %v", enum)}
+		}
+	case json.Number:
+		if kind != "" && kind != "number" && kind != "integer" {
+			return invalidType(field, kind)
+		}
+		n, err := v.Float64()
+		if err != nil {
+			return &ValidationError{Field: field, Message: "is not a valid number"}
+		}
+		if kind == "integer" {
+			if _, err := v.Int64(); err != nil {
+				return invalidType(field, kind)
+			}
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && n < minimum {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be at least %v", minimum)}
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && n > maximum {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be at most %v", maximum)}
+		}
+	case bool:
+		if kind != "" && kind != "boolean" {
+			return invalidType(field, kind)
+		}
+	default:
+		return errors.New("unexpected JSON value")
+	}
+	return nil
+}
+
+func invalidType(field string, kind string) error {
+	article := "a"
+	if kind == "object" || kind == "array" || kind == "integer" {
+		article = "an"
+	}
+	return &ValidationError{Field: field, Message: fmt.Sprintf("must be %s %s", article, kind)}
+}
+
+func joinField(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,80 @@
+package headapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecPaths(t *testing.T) {
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(Spec(), &spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+
+	for path, method := range map[string]string{
+		PathHealth:          http.MethodGet,
+		PathOpenAPI:         http.MethodGet,
+		PathExecute:         http.MethodPost,
+		PathExecuteAsync:    http.MethodPost,
+		PathExecuteAsyncJob: http.MethodGet,
+		PathExecuteBatch:    http.MethodPost,
+		PathExecuteStream:   http.MethodPost,
+		PathInstall:         http.MethodPost,
+		PathResult:          http.MethodPost,
+		PathExecutions:      http.MethodGet,
+		PathExecution:       http.MethodGet,
+	} {
+		require.Contains(t, spec.Paths, path)
+		require.Contains(t, spec.Paths[path], map[string]string{http.MethodGet: "get", http.MethodPost: "post"}[method])
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		path  string
+		body  string
+		field string // invalid field, "-" if valid
+	}{
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1"}`, "-"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1","config":{"env_vars":[{"name":"A","value":"1"}],"number_of_nodes":2,"threshold":0.5}}`, "-"},
+		{PathExecute, ``, ""},
+		{PathExecute, `[]`, ""},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm"}`, "topic"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":""}`, "topic"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":1}`, "topic"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1/reputer"}`, "-"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"reputer"}`, "topic"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"01"}`, "topic"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1","config":{"env_vars":[{"value":"1"}]}}`, "config.env_vars[0].name"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1","config":{"number_of_nodes":1.5}}`, "config.number_of_nodes"},
+		{PathExecute, `{"function_id":"bafy","method":"main.wasm","topic":"1","config":{"threshold":2}}`, "config.threshold"},
+		{PathExecuteAsync, `{"function_id":"bafy","method":"main.wasm","topic":"1","callback_url":"http://localhost"}`, "-"},
+		{PathExecuteAsync, `{"function_id":"bafy","topic":"1"}`, "method"},
+		{PathExecuteBatch, `{"requests":[{"topic":"1"}]}`, "-"},
+		{PathExecuteBatch, `{"requests":[]}`, "requests"},
+		{PathInstall, `{"uri":"https://example.com/function.tar.gz"}`, "-"},
+		{PathInstall, `{"cid":"bafy"}`, "-"},
+		{PathInstall, `{"subgroup":"a"}`, "uri"},
+		{PathResult, `{"id":"request"}`, "-"},
+		{PathResult, `{"id":""}`, "id"},
+	}
+	for _, test := range tests {
+		err := ValidateRequest(http.MethodPost, test.path, []byte(test.body))
+		if test.field == "-" {
+			require.NoError(t, err, test.body)
+			continue
+		}
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr, test.body)
+		require.Equal(t, test.field, validationErr.Field, test.body)
+	}
+
+	// Operations without request body.
+	require.NoError(t, ValidateRequest(http.MethodGet, PathExecutions, nil))
+	require.NoError(t, ValidateRequest(http.MethodGet, "/unknown", []byte("not json")))
+}
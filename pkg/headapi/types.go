@@ -0,0 +1,94 @@
+// Package headapi describes the REST API of the Allora head node: its request and response types,
+// its OpenAPI specification and a client for it.
+package headapi
+
+import (
+	"github.com/allora-network/b7s/models/codes"
+	"github.com/allora-network/b7s/models/execute"
+	"github.com/allora-network/b7s/node/aggregate"
+)
+
+// Paths of the head REST API endpoints.
+const (
+	PathHealth          = "/api/v1/health"
+	PathOpenAPI         = "/api/v1/openapi.json"
+	PathExecute         = "/api/v1/functions/execute"
+	PathExecuteAsync    = "/api/v1/functions/execute/async"
+	PathExecuteAsyncJob = "/api/v1/functions/execute/async/{id}"
+	PathExecuteBatch    = "/api/v1/functions/execute/batch"
+	PathExecuteStream   = "/api/v1/functions/execute/stream"
+	PathInstall         = "/api/v1/functions/install"
+	PathResult          = "/api/v1/functions/requests/result"
+	PathExecutions      = "/api/v1/executions"
+	PathExecution       = "/api/v1/executions/{id}"
+)
+
+// ExecuteRequest describes the payload for the REST API request for function execution.
+type ExecuteRequest struct {
+	execute.Request
+	Topic string `json:"topic,omitempty"`
+}
+
+// ExecuteResponse describes the REST API response for function execution.
+type ExecuteResponse struct {
+	Code      codes.Code        `json:"code,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Results   aggregate.Results `json:"results,omitempty"`
+	Cluster   execute.Cluster   `json:"cluster,omitempty"`
+	// Set if the execution failed.
+	Error *ExecutionError `json:"error,omitempty"`
+}
+
+// Error codes of the head API, in failed execution responses, error responses and chain submissions.
+const (
+	ErrorBadRequest       = "bad_request"       // the request is invalid (400)
+	ErrorUnknownFunction  = "unknown_function"  // the function is not installed on the workers (404)
+	ErrorNoWorkers        = "no_workers"        // no worker of the topic answered the roll call (503)
+	ErrorRollCallTimeout  = "roll_call_timeout" // fewer workers than requested answered the roll call (504)
+	ErrorExecutionFailed  = "execution_failed"  // the workers failed to execute the function (502)
+	ErrorInvalidOutput    = "invalid_output"    // the function output of the workers is invalid (502)
+	ErrorChainUnavailable = "chain_unavailable" // the chain could not be reached to follow the submission, only in chain submissions
+)
+
+// ExecutionError describes why an execution failed.
+type ExecutionError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Workers that did not return a successful result.
+	Peers []PeerFailure `json:"peers,omitempty"`
+}
+
+// PeerFailure is the failed result of a worker.
+type PeerFailure struct {
+	Peer     string     `json:"peer"`
+	Code     codes.Code `json:"code"`
+	ExitCode int        `json:"exit_code,omitempty"`
+	Stderr   string     `json:"stderr,omitempty"`
+}
+
+// InstallRequest describes the payload for the REST API request for function install. The
+// function is fetched from the URI or, without URI, from IPFS by its CID.
+type InstallRequest struct {
+	CID      string `json:"cid,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Subgroup string `json:"subgroup,omitempty"`
+}
+
+// InstallResponse describes the REST API response for function install. Code is an HTTP status
+// code, as a string.
+type InstallResponse struct {
+	Code string `json:"code"`
+}
+
+// ResultRequest describes the payload for the REST API request for the result of an execution.
+type ResultRequest struct {
+	ID string `json:"id"`
+}
+
+// ErrorResponse is the body of the REST API error responses.
+type ErrorResponse struct {
+	// Error code, such as ErrorBadRequest, for the errors having one.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}